@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/seanjh/war/internal/store"
+)
+
+var dsnFlag = flag.String("dsn", "./tmp/war.db", "SQLite data source name")
+
+const connParams = "_fk=true&_busy_timeout=5000&mode=ro"
+
+func main() {
+	flag.Parse()
+
+	readDB, err := sql.Open("sqlite3", fmt.Sprintf("%s?%s", *dsnFlag, connParams))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer readDB.Close()
+
+	ctx := context.Background()
+	games := store.NewSQL(readDB, readDB)
+	ids, err := games.ListGameIDs(ctx)
+	if err != nil {
+		log.Fatalf("Failed to check games: %v", err)
+	}
+	problems := 0
+	for _, id := range ids {
+		g, err := games.GetGame(ctx, id)
+		if err == nil {
+			err = g.CheckCards()
+		}
+		if err != nil {
+			fmt.Println(err)
+			problems++
+		}
+	}
+	if problems > 0 {
+		log.Printf("Found %d of %d games with problems", problems, len(ids))
+		os.Exit(1)
+	}
+	log.Printf("Checked %d games, every game holds its whole deck", len(ids))
+}
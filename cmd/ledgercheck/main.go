@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/db"
+	"github.com/seanjh/war/internal/ledger"
+)
+
+var dsnFlag = flag.String("dsn", "./tmp/war.db", "SQLite data source name")
+
+const connParams = "_fk=true&_busy_timeout=5000&mode=ro"
+
+func main() {
+	flag.Parse()
+
+	readDB, err := sql.Open("sqlite3", fmt.Sprintf("%s?%s", *dsnFlag, connParams))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer readDB.Close()
+
+	reader := &appcontext.AppContextDB{DB: readDB, Query: db.New(readDB)}
+	problems, err := ledger.New(reader, nil).Check(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to check ledger: %v", err)
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		log.Printf("Found %d ledger problems", len(problems))
+		os.Exit(1)
+	}
+	log.Printf("Ledger is consistent")
+}
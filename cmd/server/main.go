@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"flag"
 	"fmt"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"runtime"
@@ -15,16 +17,43 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/mattn/go-sqlite3"
 
+	"github.com/seanjh/war/internal/api"
 	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/bot"
 	"github.com/seanjh/war/internal/db"
 	"github.com/seanjh/war/internal/game"
 	"github.com/seanjh/war/internal/httputil"
+	"github.com/seanjh/war/internal/idempotency"
+	"github.com/seanjh/war/internal/rpc"
+	"github.com/seanjh/war/internal/server"
+	"github.com/seanjh/war/internal/sshserver"
+	"github.com/seanjh/war/internal/store"
 )
 
 var portFlag = flag.Int("port", 3000, "Listen port number")
 var hostFlag = flag.String("host", "localhost", "Listen hostname")
 var dsnFlag = flag.String("dsn", "file::memory:", "SQLite data source name")
 var migrateFlag = flag.Bool("migrate", false, "Run the database migrations")
+var memoryGamesFlag = flag.Bool("memory-games", false, "Keep games in memory instead of the database, losing them on exit")
+var grpcPortFlag = flag.Int("grpc-port", 0, "gRPC listen port number, or 0 to disable")
+var sshPortFlag = flag.Int("ssh-port", 0, "SSH listen port number, or 0 to disable")
+var sshHostKeyFlag = flag.String("ssh-host-key", "./tmp/ssh_host_ed25519_key", "SSH host key file, generated when missing")
+
+// dialHost returns the address to reach a server listening on host from this
+// machine. A server listening on every address is reached over loopback.
+func dialHost(host string) string {
+	if host == "" {
+		return "localhost"
+	}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil || !ip.IsUnspecified():
+		return host
+	case ip.To4() != nil:
+		return "127.0.0.1"
+	}
+	return "::1"
+}
 
 const connParams = "_fk=true&_busy_timeout=5000&_sync=1&_cache_size=1000000000&_journal=WAL&_txlock=immediate"
 
@@ -56,7 +85,13 @@ func main() {
 			Query: db.New(writeDB),
 		},
 	}
-	mux := game.SetupRoutes(httputil.SetupRoutes(http.NewServeMux()))
+	var games game.GameStore = store.NewSQL(readDB, writeDB)
+	if *memoryGamesFlag {
+		games = store.NewMemory()
+	}
+	ctx.Games = game.NewGameService(games, logger)
+	bot.Register()
+	mux := api.SetupRoutes(server.SetupRoutes(httputil.SetupRoutes(http.NewServeMux()), api.EncodeGame))
 	wrappedMux := ctx.Middleware(httputil.LogRequestMiddleware(mux, ctx.Logger))
 
 	if *migrateFlag {
@@ -70,6 +105,35 @@ func main() {
 			log.Fatalf("Failed to perform migration: %v", err)
 		}
 	}
+	go idempotency.Sweep(context.Background(), ctx.DBWriter.Query, logger, idempotency.SweepInterval)
+
+	if *grpcPortFlag != 0 {
+		lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", *hostFlag, *grpcPortFlag))
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+		log.Printf("Starting gRPC server at %s:%d\n", *hostFlag, *grpcPortFlag)
+		go func() {
+			log.Fatal(rpc.NewServer(ctx).Serve(lis))
+		}()
+	}
+
+	if *sshPortFlag != 0 {
+		hostKey, err := sshserver.LoadHostKey(*sshHostKeyFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", *hostFlag, *sshPortFlag))
+		if err != nil {
+			log.Fatalf("Failed to listen for SSH: %v", err)
+		}
+		// SSH players use the JSON API, so they share games with web players.
+		baseURL := fmt.Sprintf("http://%s", net.JoinHostPort(dialHost(*hostFlag), fmt.Sprint(*portFlag)))
+		log.Printf("Starting SSH server at %s:%d\n", *hostFlag, *sshPortFlag)
+		go func() {
+			log.Fatal(sshserver.New(ctx, hostKey, baseURL).Serve(lis))
+		}()
+	}
 
 	log.Printf("Starting server at %s:%d\n", *hostFlag, *portFlag)
 	log.Fatal(http.ListenAndServe(fmt.Sprintf("%s:%d", *hostFlag, *portFlag), wrappedMux))
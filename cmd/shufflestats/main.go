@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/seanjh/war/internal/game"
+	"github.com/seanjh/war/internal/sim"
+)
+
+var (
+	shufflesFlag = flag.Int("shuffles", 1_000_000, "number of shuffles to count")
+	rifflesFlag  = flag.Int("riffles", 7, "number of riffles in each shuffle")
+	seedFlag     = flag.Uint64("seed", 1, "seed of the shuffles")
+)
+
+func main() {
+	flag.Parse()
+
+	start := time.Now()
+	stats := sim.Shuffle(*shufflesFlag, *rifflesFlag, *seedFlag)
+	elapsed := time.Since(start)
+
+	fmt.Println("Distance from uniform of the final position of each card, by its first position")
+	cards := game.StandardDeck.Cards()
+	for from := range stats.Counts {
+		fmt.Printf("%2d %-3s %.4f\n", from, cards[from].Slug(), stats.Distance(from))
+	}
+	fmt.Printf("Max distance: %.4f\n", stats.MaxDistance())
+	log.Printf("Counted %d shuffles in %s, %.0f shuffles per minute",
+		*shufflesFlag, elapsed.Round(time.Millisecond), float64(*shufflesFlag)/elapsed.Minutes())
+}
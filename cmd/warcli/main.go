@@ -0,0 +1,123 @@
+// Command warcli plays War against a running server from the terminal.
+//
+// Usage:
+//
+//	warcli [flags]
+//	warcli [flags] create [-variant v] [-hand n] [-suit-order o] [-opponent bot] [-hot-seat]
+//	warcli [flags] join CODE
+//	warcli [flags] open GAME_ID
+//
+// Without a command, a lobby picks the game to host or join. The session is
+// printed on exit, so a game can be reopened later with -session.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/seanjh/war/internal/game"
+	"github.com/seanjh/war/internal/tui"
+	"github.com/seanjh/war/pkg/warclient"
+)
+
+var serverFlag = flag.String("server", "http://localhost:3000", "War server URL")
+var sessionFlag = flag.String("session", os.Getenv("WAR_SESSION"), "Session ID to play as, defaulting to $WAR_SESSION")
+var asciiFlag = flag.Bool("ascii", false, "Draw cards as ASCII art instead of Unicode glyphs")
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage:
+  %[1]s [flags]
+  %[1]s [flags] create [-variant v] [-hand n] [-suit-order o] [-opponent bot] [-hot-seat]
+  %[1]s [flags] join CODE
+  %[1]s [flags] open GAME_ID
+
+Flags:
+`, os.Args[0])
+	flag.PrintDefaults()
+}
+
+// createGame creates a game with the options parsed from args.
+func createGame(ctx context.Context, client *warclient.Client, args []string) (*warclient.Game, error) {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	variant := fs.String("variant", string(game.VariantClassic), "Rules variant: classic, peace, or two-beats-ace")
+	handSize := fs.Int("hand", 0, fmt.Sprintf("Hand size, from 0 to %d, where 0 flips the top card", game.MaxHandSize))
+	suitOrder := fs.String("suit-order", "", "Suit order breaking ties, like CDHS, instead of going to war")
+	opponent := fs.String("opponent", "", "Bot to play against, instead of waiting for a guest to join")
+	hotSeat := fs.Bool("hot-seat", false, "Play both seats from this terminal")
+	fs.Parse(args)
+
+	rules, err := game.NewRules(*variant, *handSize, *suitOrder)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateGame(ctx, warclient.CreateGameOptions{Rules: rules, Opponent: *opponent, HotSeat: *hotSeat})
+}
+
+// openGame returns the game named by the command line.
+func openGame(ctx context.Context, client *warclient.Client) (*warclient.Game, error) {
+	args := flag.Args()
+	switch cmd, args := args[0], args[1:]; cmd {
+	case "create":
+		return createGame(ctx, client, args)
+	case "join":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("join takes a game code")
+		}
+		return client.JoinGame(ctx, args[0])
+	case "open":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("open takes a game ID")
+		}
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid game ID '%s'", args[0])
+		}
+		return client.GetGame(ctx, id)
+	default:
+		return nil, fmt.Errorf("unknown command '%s'", cmd)
+	}
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := warclient.New(*serverFlag)
+	client.SetSessionID(*sessionFlag)
+	defer func() {
+		fmt.Fprintf(os.Stderr, "Session %s (resume with -session %[1]s)\n", client.SessionID())
+	}()
+	if flag.NArg() == 0 {
+		run(ctx, tui.NewLobby(ctx, client, *asciiFlag))
+		return
+	}
+
+	g, err := openGame(ctx, client)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		usage()
+		os.Exit(2)
+	}
+	stream, err := client.Subscribe(ctx, g.ID)
+	if err != nil {
+		log.Fatalf("Failed to subscribe to game %d: %v", g.ID, err)
+	}
+	run(ctx, tui.NewBoard(ctx, client, stream, g, *asciiFlag))
+}
+
+func run(ctx context.Context, m tea.Model) {
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx))
+	if _, err := p.Run(); err != nil && ctx.Err() == nil {
+		log.Fatalf("Failed to run the TUI: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/seanjh/war/internal/game"
+	"github.com/seanjh/war/internal/sim"
+)
+
+var (
+	gamesFlag     = flag.Int("games", 1_000_000, "number of games to play")
+	variantFlag   = flag.String("variant", string(game.VariantClassic), "War variant to play")
+	suitOrderFlag = flag.String("suit-order", "", "suits breaking ties from highest to lowest, like SHDC, instead of going to war")
+	maxRoundsFlag = flag.Int("max-rounds", game.MaxRounds, "rounds played before a game is drawn")
+	seedFlag      = flag.Uint64("seed", 1, "seed of the shuffles, each worker adding its number")
+	workersFlag   = flag.Int("workers", runtime.NumCPU(), "number of games played at once")
+)
+
+func main() {
+	flag.Parse()
+
+	rules, err := game.NewRules(*variantFlag, 0, *suitOrderFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	workers := max(1, min(*workersFlag, *gamesFlag))
+
+	start := time.Now()
+	results := make([]sim.Stats, workers)
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			games := *gamesFlag / workers
+			if w < *gamesFlag%workers {
+				games++
+			}
+			results[w] = sim.Play(sim.Config{
+				Rules:     rules,
+				Games:     games,
+				MaxRounds: *maxRoundsFlag,
+				Seed:      *seedFlag + uint64(w),
+			})
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	var stats sim.Stats
+	for _, r := range results {
+		stats.Add(r)
+	}
+	percent := func(n int) float64 {
+		return 100 * float64(n) / float64(max(1, stats.Games))
+	}
+	fmt.Printf("Games:        %d\n", stats.Games)
+	fmt.Printf("Host wins:    %d (%.2f%%)\n", stats.HostWins, percent(stats.HostWins))
+	fmt.Printf("Guest wins:   %d (%.2f%%)\n", stats.GuestWins, percent(stats.GuestWins))
+	fmt.Printf("Unfinished:   %d (%.2f%%)\n", stats.Unfinished, percent(stats.Unfinished))
+	fmt.Printf("Mean rounds:  %.1f\n", stats.MeanRounds())
+	fmt.Printf("Wars:         %d\n", stats.Wars)
+	fmt.Printf("Longest game: %d rounds\n", stats.LongestGame)
+	log.Printf("Played %d games in %s, %.0f games per minute",
+		stats.Games, elapsed.Round(time.Millisecond), float64(stats.Games)/elapsed.Minutes())
+}
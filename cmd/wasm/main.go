@@ -0,0 +1,81 @@
+//go:build js && wasm
+
+// Command wasm runs practice games of War in the browser. It is built into
+// public/war.wasm by "make wasm-build", and exposes a global warOffline object to
+// the page served at /offline, whose functions return the practice game as JSON.
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/seanjh/war/internal/bot"
+	"github.com/seanjh/war/internal/game"
+	"github.com/seanjh/war/internal/offline"
+)
+
+var practice *offline.Practice
+
+// result is the JSON returned to JavaScript: the game, or the error of the call.
+type result struct {
+	Game  *offline.View `json:"game,omitempty"`
+	Error string        `json:"error,omitempty"`
+}
+
+func respond(err error) any {
+	var res result
+	if err != nil {
+		res.Error = err.Error()
+	}
+	if practice != nil {
+		v := practice.View()
+		res.Game = &v
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		return `{"error": "failed to encode game"}`
+	}
+	return string(data)
+}
+
+// newGame deals a practice game with the options, passed as a JSON string.
+func newGame(_ js.Value, args []js.Value) any {
+	var opts offline.Options
+	if len(args) > 0 {
+		if err := json.Unmarshal([]byte(args[0].String()), &opts); err != nil {
+			return respond(err)
+		}
+	}
+	p, err := offline.New(opts, game.NewRiffleShuffler())
+	if err != nil {
+		return respond(err)
+	}
+	practice = p
+	return respond(nil)
+}
+
+func flip(_ js.Value, _ []js.Value) any {
+	if practice == nil {
+		return respond(nil)
+	}
+	return respond(practice.Flip())
+}
+
+// choose plays the card with the slug passed.
+func choose(_ js.Value, args []js.Value) any {
+	if practice == nil || len(args) == 0 {
+		return respond(nil)
+	}
+	return respond(practice.Choose(args[0].String()))
+}
+
+func main() {
+	bot.Register()
+	js.Global().Set("warOffline", js.ValueOf(map[string]any{
+		"newGame": js.FuncOf(newGame),
+		"flip":    js.FuncOf(flip),
+		"choose":  js.FuncOf(choose),
+	}))
+	js.Global().Get("document").Call("dispatchEvent", js.Global().Get("Event").New("war-offline-ready"))
+	select {}
+}
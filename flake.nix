@@ -30,6 +30,9 @@
             gotools
             air
             sqlc
+            buf
+            protoc-gen-go
+            protoc-gen-go-grpc
             go-migrate
             sqlite
             litecli
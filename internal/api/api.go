@@ -0,0 +1,270 @@
+// Package api serves games as JSON under /api/v1, sharing the game engine with
+// the HTML routes.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/game"
+	"github.com/seanjh/war/internal/session"
+)
+
+var errInvalidRequest = errors.New("invalid request")
+
+// errorStatus returns the HTTP status and error code reported for the error.
+func errorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, errInvalidRequest):
+		return http.StatusBadRequest, "invalid_request"
+	case errors.Is(err, game.ErrInvalidVariant),
+		errors.Is(err, game.ErrInvalidHandSize),
+		errors.Is(err, game.ErrInvalidSuitOrder),
+		errors.Is(err, game.ErrInvalidSeating):
+		return http.StatusBadRequest, "invalid_rules"
+	case errors.Is(err, game.ErrInvalidMove):
+		return http.StatusBadRequest, "invalid_move"
+	case errors.Is(err, game.ErrNotSeated):
+		return http.StatusForbidden, "not_seated"
+	case errors.Is(err, game.ErrGameNotFound):
+		return http.StatusNotFound, "not_found"
+	case errors.Is(err, game.ErrGameOver):
+		return http.StatusConflict, "game_over"
+	case errors.Is(err, game.ErrSeatTaken):
+		return http.StatusConflict, "seat_taken"
+	case errors.Is(err, game.ErrConflict):
+		return http.StatusConflict, "conflict"
+	}
+	return http.StatusInternalServerError, "internal"
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		ctx := appcontext.GetAppContext(r)
+		ctx.Logger.Error("Failed to encode response", "err", err)
+	}
+}
+
+// writeError writes the error body for err. Unexpected errors are logged, and
+// reported without their details.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status, code := errorStatus(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		ctx := appcontext.GetAppContext(r)
+		ctx.Logger.Error("API request failed",
+			"err", err,
+			"method", r.Method,
+			"path", r.URL.Path)
+		message = "internal error"
+	}
+	writeJSON(w, r, status, Error{Error: ErrorDetail{Code: code, Message: message}})
+}
+
+// decode reads the JSON request body into v. An empty body leaves v unchanged.
+func decode(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: %w", errInvalidRequest, err)
+	}
+	return nil
+}
+
+// requireSession returns the request session, opening a new one when there is none.
+func requireSession(w http.ResponseWriter, r *http.Request) (*session.Session, error) {
+	s := session.GetSession(r)
+	if s.ID != "" {
+		return s, nil
+	}
+	return session.OpenNewSession(w, r)
+}
+
+// games returns the service playing the request's games.
+func games(r *http.Request) *game.GameService {
+	return appcontext.GetAppContext(r).Games
+}
+
+func CreateGame(w http.ResponseWriter, r *http.Request) {
+	var req CreateGameRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	rules, err := game.NewRules(req.Variant, req.HandSize, req.SuitOrder)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	seating := game.Seating{GuestBot: req.Opponent, HotSeat: req.HotSeat}
+	if err := seating.Validate(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	s, err := requireSession(w, r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	g, err := games(r).CreateGame(r.Context(), s.ID, rules, seating)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/games/%d", g.ID))
+	writeJSON(w, r, http.StatusCreated, NewGame(g, s.ID))
+}
+
+func JoinGame(w http.ResponseWriter, r *http.Request) {
+	var req JoinGameRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	s, err := requireSession(w, r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	g, err := games(r).JoinGame(r.Context(), req.Code, s.ID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, NewGame(g, s.ID))
+}
+
+func GetGame(w http.ResponseWriter, r *http.Request) {
+	id, err := game.ParseGameID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	g, err := games(r).GetGame(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, NewGame(g, session.GetSession(r).ID))
+}
+
+func GetRounds(w http.ResponseWriter, r *http.Request) {
+	id, err := game.ParseGameID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	battles, err := games(r).GetRounds(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	data := Rounds{Rounds: make([]Round, len(battles))}
+	for i, b := range battles {
+		data.Rounds[i] = Round{Number: i + 1, Battle: NewBattle(b)}
+	}
+	writeJSON(w, r, http.StatusOK, data)
+}
+
+func Flip(w http.ResponseWriter, r *http.Request) {
+	var req MoveRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := game.ParseGameID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	s := session.GetSession(r)
+	g, err := games(r).Flip(r.Context(), id, s.ID, game.ParseGameRole(req.Role))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, NewGame(g, s.ID))
+}
+
+func Choose(w http.ResponseWriter, r *http.Request) {
+	var req MoveRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := game.ParseGameID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	s := session.GetSession(r)
+	g, err := games(r).Choose(r.Context(), id, s.ID, game.ParseGameRole(req.Role), req.Card)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, NewGame(g, s.ID))
+}
+
+// Events streams every change to the game as server-sent events, until the client
+// disconnects. Each event is named by its type, and its data is an Event.
+func Events(w http.ResponseWriter, r *http.Request) {
+	id, err := game.ParseGameID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	g, err := games(r).GetGame(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, errors.New("response does not support streaming"))
+		return
+	}
+	events, cancel := games(r).Subscribe(g.ID)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-events:
+			data, err := json.Marshal(NewEvent(e))
+			if err != nil {
+				ctx := appcontext.GetAppContext(r)
+				ctx.Logger.Error("Failed to encode event", "err", err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func SetupRoutes(mux *http.ServeMux) *http.ServeMux {
+	mux.HandleFunc("GET /api/openapi.json", GetSpec)
+	mux.Handle("POST /api/v1/games", session.WithSessionMiddleware(http.HandlerFunc(CreateGame)))
+	mux.Handle("POST /api/v1/games/join", session.WithSessionMiddleware(http.HandlerFunc(JoinGame)))
+	mux.Handle("GET /api/v1/games/{id}", session.WithSessionMiddleware(http.HandlerFunc(GetGame)))
+	mux.Handle("GET /api/v1/games/{id}/rounds", session.WithSessionMiddleware(http.HandlerFunc(GetRounds)))
+	mux.Handle("GET /api/v1/games/{id}/events", session.WithSessionMiddleware(http.HandlerFunc(Events)))
+	mux.Handle("POST /api/v1/games/{id}/flip", session.WithSessionMiddleware(http.HandlerFunc(Flip)))
+	mux.Handle("POST /api/v1/games/{id}/choose", session.WithSessionMiddleware(http.HandlerFunc(Choose)))
+	return mux
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/db"
+	"github.com/seanjh/war/internal/db/dbtest"
+	"github.com/seanjh/war/internal/game"
+	"github.com/seanjh/war/internal/store"
+)
+
+// newTestServer returns the API routes backed by a migrated in-memory database,
+// with the sessions "host" and "guest" stored in it. Every response is checked
+// against the spec.
+func newTestServer(t *testing.T) http.Handler {
+	return newSpecServer(t, make(map[string]bool))
+}
+
+// newSpecServer returns the routes of newTestServer, recording the ID of every
+// operation served in seen.
+func newSpecServer(t *testing.T, seen map[string]bool) http.Handler {
+	t.Helper()
+	conn := dbtest.Open(t)
+	_, err := conn.Exec(`INSERT INTO sessions (id) VALUES ('host'), ('guest')`)
+	require.NoError(t, err)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	d := &appcontext.AppContextDB{DB: conn, Query: db.New(conn)}
+	ctx := &appcontext.AppContext{
+		Logger:   logger,
+		DBReader: d,
+		DBWriter: d,
+		Games:    game.NewGameService(store.NewSQL(conn, conn), logger),
+	}
+	return validateResponses(t, ctx.Middleware(SetupRoutes(http.NewServeMux())), seen)
+}
+
+func do(t *testing.T, h http.Handler, sessionID, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.AddCookie(&http.Cookie{Name: "session-id", Value: sessionID})
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func decodeBody[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &v), w.Body.String())
+	return v
+}
+
+func TestPlayGame(t *testing.T) {
+	h := newTestServer(t)
+
+	w := do(t, h, "host", http.MethodPost, "/api/v1/games", `{"variant": "peace"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "/api/v1/games/1", w.Header().Get("Location"))
+	g := decodeBody[Game](t, w)
+	assert.Equal(t, "peace", g.Rules.Variant)
+	require.Len(t, g.Players, 2)
+	assert.True(t, g.Players[0].Controllable)
+	assert.False(t, g.Players[1].Seated)
+
+	w = do(t, h, "guest", http.MethodPost, "/api/v1/games/join", `{"code": "`+strings.ToLower(g.Code)+`"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	g = decodeBody[Game](t, w)
+	assert.True(t, g.Players[1].Seated)
+	assert.True(t, g.Players[1].Controllable)
+	assert.False(t, g.Players[0].Controllable)
+
+	w = do(t, h, "host", http.MethodPost, "/api/v1/games/1/flip", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	g = decodeBody[Game](t, w)
+	assert.True(t, g.Players[0].Flipped)
+	assert.Nil(t, g.Battle)
+
+	w = do(t, h, "guest", http.MethodPost, "/api/v1/games/1/flip", `{"role": "guest"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	g = decodeBody[Game](t, w)
+	require.NotNil(t, g.Battle)
+	assert.Len(t, g.Battle.Cards, 2)
+	assert.NotEmpty(t, g.Battle.Log)
+	assert.Equal(t, 52, g.Players[0].DeckSize+g.Players[1].DeckSize)
+
+	w = do(t, h, "", http.MethodGet, "/api/v1/games/1/rounds", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	rounds := decodeBody[Rounds](t, w)
+	require.Len(t, rounds.Rounds, 1)
+	assert.Equal(t, 1, rounds.Rounds[0].Number)
+	assert.Equal(t, g.Battle.Cards, rounds.Rounds[0].Cards)
+	assert.Equal(t, g.Battle.Winner, rounds.Rounds[0].Winner)
+	assert.Equal(t, g.Battle.Log, rounds.Rounds[0].Log)
+
+	w = do(t, h, "", http.MethodGet, "/api/v1/games/1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	g = decodeBody[Game](t, w)
+	assert.Nil(t, g.Battle)
+	for _, p := range g.Players {
+		assert.False(t, p.Controllable)
+		assert.Empty(t, p.Hand)
+	}
+}
+
+func TestChooseHidesOpponentHand(t *testing.T) {
+	h := newTestServer(t)
+	w := do(t, h, "host", http.MethodPost, "/api/v1/games", `{"hand_size": 3, "hot_seat": false}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	g := decodeBody[Game](t, w)
+	require.Len(t, g.Players[0].Hand, 3)
+	assert.Empty(t, g.Players[1].Hand)
+	assert.Equal(t, 3, g.Players[1].HandSize)
+
+	card := g.Players[0].Hand[1]
+	w = do(t, h, "host", http.MethodPost, "/api/v1/games/1/choose", `{"card": "`+card+`"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	g = decodeBody[Game](t, w)
+	assert.Equal(t, card, g.Players[0].Chosen)
+	assert.NotEmpty(t, g.Players[0].Commitment)
+
+	w = do(t, h, "guest", http.MethodGet, "/api/v1/games/1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	g = decodeBody[Game](t, w)
+	assert.Empty(t, g.Players[0].Chosen)
+	assert.NotEmpty(t, g.Players[0].Commitment)
+}
+
+func TestCreateGameOpensSession(t *testing.T) {
+	h := newTestServer(t)
+	w := do(t, h, "", http.MethodPost, "/api/v1/games", "")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "session-id", cookies[0].Name)
+	g := decodeBody[Game](t, w)
+	assert.True(t, g.Players[0].Controllable)
+}
+
+func TestErrors(t *testing.T) {
+	h := newTestServer(t)
+	w := do(t, h, "host", http.MethodPost, "/api/v1/games", "")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	tests := []struct {
+		name      string
+		sessionID string
+		method    string
+		path      string
+		body      string
+		status    int
+		code      string
+	}{
+		{"malformed body", "host", http.MethodPost, "/api/v1/games", `{"variant":`, http.StatusBadRequest, "invalid_request"},
+		{"unknown field", "host", http.MethodPost, "/api/v1/games", `{"colour": "red"}`, http.StatusBadRequest, "invalid_request"},
+		{"unknown variant", "host", http.MethodPost, "/api/v1/games", `{"variant": "bogus"}`, http.StatusBadRequest, "invalid_rules"},
+		{"hand size", "host", http.MethodPost, "/api/v1/games", `{"hand_size": 9}`, http.StatusBadRequest, "invalid_rules"},
+		{"suit order", "host", http.MethodPost, "/api/v1/games", `{"suit_order": "SSSS"}`, http.StatusBadRequest, "invalid_rules"},
+		{"unknown opponent", "host", http.MethodPost, "/api/v1/games", `{"opponent": "nobody"}`, http.StatusBadRequest, "invalid_rules"},
+		{"unknown game", "host", http.MethodGet, "/api/v1/games/99", "", http.StatusNotFound, "not_found"},
+		{"invalid game id", "host", http.MethodGet, "/api/v1/games/abc", "", http.StatusNotFound, "not_found"},
+		{"unknown code", "guest", http.MethodPost, "/api/v1/games/join", `{"code": "nope"}`, http.StatusNotFound, "not_found"},
+		{"not seated", "guest", http.MethodPost, "/api/v1/games/1/flip", "", http.StatusForbidden, "not_seated"},
+		{"wrong seat", "host", http.MethodPost, "/api/v1/games/1/flip", `{"role": "guest"}`, http.StatusForbidden, "not_seated"},
+		{"choose in classic", "host", http.MethodPost, "/api/v1/games/1/choose", `{"card": "2C"}`, http.StatusBadRequest, "invalid_move"},
+		{"missing card", "host", http.MethodPost, "/api/v1/games/1/choose", "", http.StatusBadRequest, "invalid_move"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, h, tt.sessionID, tt.method, tt.path, tt.body)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			body := decodeBody[Error](t, w)
+			assert.Equal(t, tt.code, body.Error.Code)
+			assert.NotEmpty(t, body.Error.Message)
+		})
+	}
+}
+
+func TestJoinFullGame(t *testing.T) {
+	h := newTestServer(t)
+	w := do(t, h, "host", http.MethodPost, "/api/v1/games", `{"hot_seat": true}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	g := decodeBody[Game](t, w)
+
+	w = do(t, h, "guest", http.MethodPost, "/api/v1/games/join", `{"code": "`+g.Code+`"}`)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	assert.Equal(t, "seat_taken", decodeBody[Error](t, w).Error.Code)
+
+	w = do(t, h, "host", http.MethodPost, "/api/v1/games/join", `{"code": "`+g.Code+`"}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
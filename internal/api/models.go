@@ -0,0 +1,212 @@
+package api
+
+import "github.com/seanjh/war/internal/game"
+
+// Cards are represented by their slugs, like "10C" or "AD", and decks by arrays of
+// slugs. Roles are "host" or "guest", and are omitted when unknown.
+
+type Rules struct {
+	Variant   string `json:"variant"`
+	SuitOrder string `json:"suit_order,omitempty"`
+	HandSize  int    `json:"hand_size"`
+}
+
+// Player is a seat at the game. Only the sessions controlling the seat see its hand.
+type Player struct {
+	Role     string `json:"role"`
+	DeckSize int    `json:"deck_size"`
+	HandSize int    `json:"hand_size"`
+	// Hand is only set for the players the session controls.
+	Hand       []string `json:"hand,omitempty"`
+	Chosen     string   `json:"chosen,omitempty"`
+	Flipped    bool     `json:"flipped"`
+	Moved      bool     `json:"moved"`
+	Commitment string   `json:"commitment,omitempty"`
+	Bot        string   `json:"bot,omitempty"`
+	// Seated is true when a session or a bot owns the seat.
+	Seated       bool `json:"seated"`
+	Controllable bool `json:"controllable"`
+}
+
+type Reveal struct {
+	Card  string `json:"card"`
+	Nonce string `json:"nonce"`
+}
+
+// Battle is a round played. Cards holds the card each role played first, and War
+// the cards each role committed to a war.
+type Battle struct {
+	Cards   map[string]string   `json:"cards"`
+	War     map[string][]string `json:"war,omitempty"`
+	Winner  string              `json:"winner,omitempty"`
+	Reveals map[string]Reveal   `json:"reveals,omitempty"`
+	Log     []string            `json:"log"`
+}
+
+type Game struct {
+	ID      int      `json:"id"`
+	Code    string   `json:"code"`
+	Rules   Rules    `json:"rules"`
+	HotSeat bool     `json:"hot_seat"`
+	Players []Player `json:"players"`
+	// Battle is the round played by the request, if any.
+	Battle *Battle `json:"battle,omitempty"`
+	Winner string  `json:"winner,omitempty"`
+	// Drawn is true once the game ended without a winner after game.MaxRounds.
+	Drawn bool `json:"drawn,omitempty"`
+}
+
+type Round struct {
+	Number int `json:"number"`
+	Battle
+}
+
+type Rounds struct {
+	Rounds []Round `json:"rounds"`
+}
+
+// Event tells subscribers that a game changed.
+type Event struct {
+	Type   string  `json:"type"`
+	GameID int     `json:"game_id"`
+	Role   string  `json:"role,omitempty"`
+	Battle *Battle `json:"battle,omitempty"`
+}
+
+type CreateGameRequest struct {
+	Variant   string `json:"variant"`
+	HandSize  int    `json:"hand_size"`
+	SuitOrder string `json:"suit_order"`
+	Opponent  string `json:"opponent"`
+	HotSeat   bool   `json:"hot_seat"`
+}
+
+type JoinGameRequest struct {
+	Code string `json:"code"`
+}
+
+// MoveRequest picks the seat to move for, when the session controls more than
+// one, and the card to choose in the hand War variant.
+type MoveRequest struct {
+	Role string `json:"role"`
+	Card string `json:"card"`
+}
+
+type ErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error is the body of every failed request.
+type Error struct {
+	Error ErrorDetail `json:"error"`
+}
+
+func role(r game.GameRole) string {
+	if r == game.Unknown {
+		return ""
+	}
+	return r.String()
+}
+
+func slugs(d game.Deck) []string {
+	s := make([]string, len(d))
+	for i, c := range d {
+		s[i] = c.Slug()
+	}
+	return s
+}
+
+// EncodeGame returns the JSON representation of the game as seen by the session,
+// as a server.Encoder, so the game pages answer clients preferring JSON like
+// the API.
+func EncodeGame(g *game.Game, sessionID string) any {
+	return NewGame(g, sessionID)
+}
+
+// NewGame returns the JSON representation of the game as seen by the session.
+func NewGame(g *game.Game, sessionID string) Game {
+	data := Game{
+		ID:   g.ID,
+		Code: g.Code,
+		Rules: Rules{
+			Variant:   string(g.Rules.Variant),
+			SuitOrder: g.Rules.SuitOrder.String(),
+			HandSize:  g.Rules.HandSize,
+		},
+		HotSeat: g.HotSeat,
+		Players: make([]Player, 0, 2),
+	}
+	for _, p := range g.Players() {
+		data.Players = append(data.Players, NewPlayer(g, p, sessionID))
+	}
+	if g.Battle != nil {
+		b := NewBattle(g.Battle)
+		data.Battle = &b
+	}
+	if w := g.Winner(); w != nil {
+		data.Winner = role(w.Role)
+	}
+	data.Drawn = g.Drawn()
+	return data
+}
+
+// NewPlayer returns the JSON representation of the player as seen by the session.
+func NewPlayer(g *game.Game, p *game.Player, sessionID string) Player {
+	data := Player{
+		Role:         role(p.Role),
+		DeckSize:     len(p.Deck),
+		HandSize:     len(p.Hand),
+		Flipped:      p.Flipped,
+		Moved:        g.HasMoved(p),
+		Commitment:   string(p.Commitment),
+		Bot:          p.Bot,
+		Seated:       p.SessionID != "" || p.Bot != "" || (g.HotSeat && p.Role == game.Guest),
+		Controllable: g.Controls(sessionID, p),
+	}
+	if data.Controllable {
+		data.Hand = slugs(p.Hand)
+		if c := p.ChosenCard(); c != nil {
+			data.Chosen = c.Slug()
+		}
+	}
+	return data
+}
+
+// NewEvent returns the JSON representation of the event.
+func NewEvent(e game.Event) Event {
+	data := Event{Type: string(e.Type), GameID: e.GameID, Role: role(e.Role)}
+	if e.Battle != nil {
+		b := NewBattle(e.Battle)
+		data.Battle = &b
+	}
+	return data
+}
+
+// NewBattle returns the JSON representation of the round.
+func NewBattle(b *game.Battle) Battle {
+	data := Battle{
+		Cards:  make(map[string]string, len(b.Battle)),
+		Winner: role(b.Winner),
+		Log:    b.Log,
+	}
+	for r, c := range b.Battle {
+		data.Cards[r] = c.Slug()
+	}
+	if len(b.War) > 0 {
+		data.War = make(map[string][]string, len(b.War))
+		for r, d := range b.War {
+			data.War[r] = slugs(d)
+		}
+	}
+	if len(b.Reveals) > 0 {
+		data.Reveals = make(map[string]Reveal, len(b.Reveals))
+		for r, choice := range b.Reveals {
+			data.Reveals[r] = Reveal{Card: choice.Card.Slug(), Nonce: choice.Nonce}
+		}
+	}
+	if data.Log == nil {
+		data.Log = make([]string, 0)
+	}
+	return data
+}
@@ -0,0 +1,16 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// Spec is the OpenAPI document describing every route served by the API.
+//
+//go:embed openapi.json
+var Spec []byte
+
+func GetSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(Spec)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadSpec(t *testing.T) (*openapi3.T, routers.Router) {
+	t.Helper()
+	openapi3filter.RegisterBodyDecoder("text/event-stream", openapi3filter.RegisteredBodyDecoder("text/plain"))
+	doc, err := openapi3.NewLoader().LoadFromData(Spec)
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+	return doc, router
+}
+
+// validateResponses checks every response served by h against the spec, and
+// records the ID of every operation served in seen.
+func validateResponses(t *testing.T, h http.Handler, seen map[string]bool) http.Handler {
+	_, router := loadSpec(t)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+
+		route, params, err := router.FindRoute(r)
+		require.NoError(t, err, "%s %s is not in the spec", r.Method, r.URL.Path)
+		seen[route.Operation.OperationID] = true
+		input := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: params,
+				Route:      route,
+			},
+			Status:  rec.Code,
+			Header:  rec.Header(),
+			Body:    io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+			Options: &openapi3filter.Options{IncludeResponseStatus: true},
+		}
+		assert.NoError(t, openapi3filter.ValidateResponse(r.Context(), input),
+			"%s %s: %d %s", r.Method, r.URL.Path, rec.Code, rec.Body.String())
+
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	})
+}
+
+func TestSpecCoversEveryOperation(t *testing.T) {
+	doc, _ := loadSpec(t)
+	seen := make(map[string]bool)
+	h := newSpecServer(t, seen)
+
+	w := do(t, h, "host", http.MethodPost, "/api/v1/games", `{"hand_size": 1}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	g := decodeBody[Game](t, w)
+	w = do(t, h, "guest", http.MethodPost, "/api/v1/games/join", `{"code": "`+g.Code+`"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	for _, sessionID := range []string{"host", "guest"} {
+		w = do(t, h, sessionID, http.MethodGet, "/api/v1/games/1", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		g = decodeBody[Game](t, w)
+		for _, p := range g.Players {
+			if p.Controllable {
+				w = do(t, h, sessionID, http.MethodPost, "/api/v1/games/1/choose", `{"card": "`+p.Hand[0]+`"}`)
+				require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			}
+		}
+	}
+	w = do(t, h, "host", http.MethodPost, "/api/v1/games/1/flip", "")
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	w = do(t, h, "host", http.MethodGet, "/api/v1/games/1/rounds", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/games/1/events", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	w = do(t, h, "", http.MethodGet, "/api/openapi.json", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	for path, item := range doc.Paths.Map() {
+		for method, op := range item.Operations() {
+			assert.True(t, seen[op.OperationID], "%s %s was not exercised", method, path)
+		}
+	}
+}
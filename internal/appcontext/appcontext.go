@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/seanjh/war/internal/db"
+	"github.com/seanjh/war/internal/game"
 )
 
 type AppContextDB struct {
@@ -18,6 +19,7 @@ type AppContext struct {
 	Logger   *slog.Logger
 	DBReader *AppContextDB
 	DBWriter *AppContextDB
+	Games    *game.GameService
 }
 
 type key string
@@ -27,14 +29,23 @@ const appContextKey key = "appcontext"
 // Middleware adds the application context to the request context.
 func (c *AppContext) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := context.WithValue(r.Context(), appContextKey, c)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r.WithContext(c.WithContext(r.Context())))
 	})
 }
 
+// WithContext returns a copy of ctx carrying the application context.
+func (c *AppContext) WithContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, appContextKey, c)
+}
+
 // GetAppContext returns the application context for the request.
 func GetAppContext(r *http.Request) *AppContext {
-	ctx, ok := r.Context().Value(appContextKey).(*AppContext)
+	return FromContext(r.Context())
+}
+
+// FromContext returns the application context carried by ctx.
+func FromContext(c context.Context) *AppContext {
+	ctx, ok := c.Value(appContextKey).(*AppContext)
 	if !ok {
 		panic("Failed to load app context from request")
 	}
@@ -0,0 +1,145 @@
+package bot
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/seanjh/war/internal/game"
+)
+
+// Opponent is what a bot can see of another player at the table.
+type Opponent struct {
+	Role     game.GameRole
+	DeckSize int
+	HandSize int
+	Moved    bool
+}
+
+// State is everything a bot can see when it is asked to move: its own hand, and
+// only the sizes of every deck and opponent's hand.
+type State struct {
+	Rules     game.Rules
+	Role      game.GameRole
+	DeckSize  int
+	Hand      game.Deck
+	Opponents []Opponent
+}
+
+// NewState returns the State visible to the player seated in the game.
+func NewState(g *game.Game, p *game.Player) State {
+	s := State{
+		Rules:    g.Rules,
+		Role:     p.Role,
+		DeckSize: len(p.Deck),
+		Hand:     slices.Clone(p.Hand),
+	}
+	for _, o := range g.Players() {
+		if o == p {
+			continue
+		}
+		s.Opponents = append(s.Opponents, Opponent{
+			Role:     o.Role,
+			DeckSize: len(o.Deck),
+			HandSize: len(o.Hand),
+			Moved:    g.HasMoved(o),
+		})
+	}
+	return s
+}
+
+// Move is a bot's decision for a round: flip the top card of its deck, or play
+// a card from its hand.
+type Move struct {
+	Flip bool
+	Card game.Card
+}
+
+// Strategy decides a bot's move from its visible State.
+type Strategy interface {
+	Move(s State) Move
+}
+
+// Seat plays a game seat with a Strategy, as a game.Autoplayer.
+type Seat struct {
+	Strategy Strategy
+}
+
+func (s Seat) Autoplay(g *game.Game, p *game.Player) error {
+	m := s.Strategy.Move(NewState(g, p))
+	if m.Flip {
+		return g.FlipFor(p)
+	}
+	nonce, err := game.NewNonce()
+	if err != nil {
+		return fmt.Errorf("failed to create nonce: %w", err)
+	}
+	return g.ChooseFor(p, m.Card, nonce)
+}
+
+const (
+	NameAutoFlip   = "auto-flip"
+	NameRandom     = "random"
+	NameGreedy     = "greedy"
+	NameMonteCarlo = "monte-carlo"
+)
+
+// Register makes every built-in bot available to occupy game seats.
+func Register() {
+	game.RegisterAutoplayer(NameAutoFlip, Seat{Strategy: AutoFlip{}})
+	game.RegisterAutoplayer(NameRandom, Seat{Strategy: NewRandom()})
+	game.RegisterAutoplayer(NameGreedy, Seat{Strategy: Greedy{}})
+	game.RegisterAutoplayer(NameMonteCarlo, Seat{Strategy: NewMonteCarlo(defaultSamples)})
+}
+
+// intN returns a random int in [0,n) from r, or from the shared, concurrency-safe
+// source when r is nil.
+func intN(r *rand.Rand, n int) int {
+	if r == nil {
+		return rand.IntN(n)
+	}
+	return r.IntN(n)
+}
+
+// AutoFlip flips whenever it can, and otherwise plays the first card in its hand.
+type AutoFlip struct{}
+
+func (AutoFlip) Move(s State) Move {
+	if len(s.Hand) == 0 {
+		return Move{Flip: true}
+	}
+	return Move{Card: s.Hand[0]}
+}
+
+// Random plays a card from its hand chosen uniformly at random.
+type Random struct {
+	rand *rand.Rand
+}
+
+func NewRandom() *Random {
+	return &Random{}
+}
+
+func (r *Random) Move(s State) Move {
+	if len(s.Hand) == 0 {
+		return Move{Flip: true}
+	}
+	return Move{Card: s.Hand[intN(r.rand, len(s.Hand))]}
+}
+
+// Greedy always plays the highest ranked card in its hand.
+type Greedy struct{}
+
+func (Greedy) Move(s State) Move {
+	if len(s.Hand) == 0 {
+		return Move{Flip: true}
+	}
+	ranker := s.Rules.Ranker()
+	best := s.Hand[0]
+	for _, c := range s.Hand[1:] {
+		if ranker.Compare(c, best) > 0 {
+			best = c
+		}
+	}
+	return Move{Card: best}
+}
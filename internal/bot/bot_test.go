@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seanjh/war/internal/game"
+)
+
+func TestStrategies(t *testing.T) {
+	hand := game.Deck{{Suit: "C", Value: 5}, {Suit: "H", Value: game.King}, {Suit: "D", Value: 2}}
+	classic := game.Rules{Variant: game.VariantClassic, HandSize: 3}
+	peace := game.Rules{Variant: game.VariantPeace, HandSize: 3}
+
+	testCases := []struct {
+		scenario string
+		strategy Strategy
+		state    State
+		expected Move
+	}{
+		{"auto-flip flips", AutoFlip{}, State{}, Move{Flip: true}},
+		{"auto-flip plays first card", AutoFlip{}, State{Rules: classic, Hand: hand}, Move{Card: hand[0]}},
+		{"random flips", NewRandom(), State{}, Move{Flip: true}},
+		{"greedy flips", Greedy{}, State{}, Move{Flip: true}},
+		{"greedy plays highest", Greedy{}, State{Rules: classic, Hand: hand}, Move{Card: hand[1]}},
+		{"greedy plays lowest in peace", Greedy{}, State{Rules: peace, Hand: hand}, Move{Card: hand[2]}},
+		{"monte carlo flips", NewMonteCarlo(10), State{}, Move{Flip: true}},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.scenario, func(t *testing.T) {
+			assert.Equal(t, c.expected, c.strategy.Move(c.state))
+		})
+	}
+}
+
+func TestRandomPlaysFromHand(t *testing.T) {
+	hand := game.Deck{{Suit: "C", Value: 5}, {Suit: "H", Value: game.King}, {Suit: "D", Value: 2}}
+	r := &Random{rand: rand.New(rand.NewPCG(1, 2))}
+	for range 20 {
+		assert.Contains(t, hand, r.Move(State{Hand: hand}).Card)
+	}
+}
+
+func TestMonteCarloPrefersWinningCard(t *testing.T) {
+	m := &MonteCarlo{Samples: 100, rand: rand.New(rand.NewPCG(1, 2))}
+	state := State{
+		Rules:     game.Rules{Variant: game.VariantClassic, HandSize: 2},
+		Hand:      game.Deck{{Suit: "C", Value: 2}, {Suit: "S", Value: game.Ace}},
+		Opponents: []Opponent{{Role: game.Host, HandSize: 1}},
+	}
+	assert.Equal(t, Move{Card: game.Card{Suit: "S", Value: game.Ace}}, m.Move(state))
+
+	state.Rules.Variant = game.VariantPeace
+	assert.Equal(t, Move{Card: game.Card{Suit: "C", Value: 2}}, m.Move(state))
+}
+
+func TestSeatAutoplay(t *testing.T) {
+	t.Run("flip", func(t *testing.T) {
+		guest := &game.Player{Role: game.Guest, Deck: game.Deck{{Suit: "C", Value: 5}}}
+		g := &game.Game{
+			Player1: &game.Player{Role: game.Host, Deck: game.Deck{{Suit: "H", Value: 5}}},
+			Player2: guest,
+		}
+		require.NoError(t, Seat{Strategy: Greedy{}}.Autoplay(g, guest))
+		assert.True(t, guest.Flipped)
+	})
+
+	t.Run("choose", func(t *testing.T) {
+		guest := &game.Player{Role: game.Guest, Hand: game.Deck{{Suit: "C", Value: 5}, {Suit: "C", Value: 9}}}
+		g := &game.Game{
+			Rules:   game.Rules{HandSize: 2},
+			Player1: &game.Player{Role: game.Host, Hand: game.Deck{{Suit: "H", Value: 5}, {Suit: "H", Value: 9}}},
+			Player2: guest,
+		}
+		require.NoError(t, Seat{Strategy: Greedy{}}.Autoplay(g, guest))
+		assert.Equal(t, &game.Card{Suit: "C", Value: 9}, guest.ChosenCard())
+	})
+
+	t.Run("state hides opponent hand", func(t *testing.T) {
+		host := &game.Player{Role: game.Host, Hand: game.Deck{{Suit: "H", Value: 5}}, Deck: game.Deck{{Suit: "H", Value: 2}}}
+		guest := &game.Player{Role: game.Guest, Hand: game.Deck{{Suit: "C", Value: 5}}}
+		g := &game.Game{Rules: game.Rules{HandSize: 1}, Player1: host, Player2: guest}
+		s := NewState(g, guest)
+		assert.Equal(t, game.Deck{{Suit: "C", Value: 5}}, s.Hand)
+		assert.Equal(t, []Opponent{{Role: game.Host, DeckSize: 1, HandSize: 1}}, s.Opponents)
+	})
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"math/rand/v2"
+	"slices"
+
+	"github.com/seanjh/war/internal/game"
+)
+
+// defaultSamples is the number of playouts the Monte Carlo bot runs per card.
+const defaultSamples = 200
+
+// MonteCarlo estimates the value of each card in its hand by sampling the hidden
+// cards its opponent could hold, and playing out the rest of both hands at random.
+// It plays the card with the best average number of cards won.
+type MonteCarlo struct {
+	Samples int
+	rand    *rand.Rand
+}
+
+func NewMonteCarlo(samples int) *MonteCarlo {
+	return &MonteCarlo{Samples: samples}
+}
+
+func (m *MonteCarlo) Move(s State) Move {
+	if len(s.Hand) == 0 {
+		return Move{Flip: true}
+	}
+	if len(s.Hand) == 1 {
+		return Move{Card: s.Hand[0]}
+	}
+
+	handSize := len(s.Hand)
+	for _, o := range s.Opponents {
+		if o.HandSize > 0 {
+			handSize = o.HandSize
+		}
+	}
+	unseen := make(game.Deck, 0)
+	for _, c := range game.NewDeck() {
+		if !slices.Contains(s.Hand, c) {
+			unseen = append(unseen, c)
+		}
+	}
+	handSize = min(handSize, len(unseen))
+
+	ranker := s.Rules.Ranker()
+	best, bestScore := s.Hand[0], 0
+	for i, c := range s.Hand {
+		rest := slices.Delete(slices.Clone(s.Hand), i, i+1)
+		score := 0
+		for range m.Samples {
+			m.shuffle(unseen)
+			score += m.playout(c, rest, slices.Clone(unseen[:handSize]), ranker)
+		}
+		if i == 0 || score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return Move{Card: best}
+}
+
+func (m *MonteCarlo) shuffle(d game.Deck) {
+	swap := func(i, j int) { d[i], d[j] = d[j], d[i] }
+	if m.rand == nil {
+		rand.Shuffle(len(d), swap)
+	} else {
+		m.rand.Shuffle(len(d), swap)
+	}
+}
+
+// playout plays the first card against a random card from the opponent's hand,
+// then plays both remaining hands against each other at random. It returns the
+// net number of cards won, where ties win nothing.
+func (m *MonteCarlo) playout(first game.Card, rest game.Deck, opponent game.Deck, ranker game.Ranker) int {
+	rest = slices.Clone(rest)
+	mine := first
+	net := 0
+	for len(opponent) > 0 {
+		j := intN(m.rand, len(opponent))
+		theirs := opponent[j]
+		opponent = slices.Delete(opponent, j, j+1)
+
+		switch c := ranker.Compare(mine, theirs); {
+		case c > 0:
+			net++
+		case c < 0:
+			net--
+		}
+
+		if len(rest) == 0 {
+			break
+		}
+		i := intN(m.rand, len(rest))
+		mine = rest[i]
+		rest = slices.Delete(rest, i, i+1)
+	}
+	return net
+}
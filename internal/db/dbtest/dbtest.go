@@ -0,0 +1,60 @@
+// Package dbtest opens in-memory SQLite databases migrated like the server's,
+// for tests of packages that query the database.
+package dbtest
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+// Open returns an in-memory database with every up migration applied. It is
+// closed when the test ends.
+func Open(t testing.TB) *sql.DB {
+	t.Helper()
+	conn := OpenEmpty(t)
+	Migrate(t, conn, Migrations(t, "up")...)
+	return conn
+}
+
+// OpenEmpty returns an in-memory database without any migration applied. It is
+// closed when the test ends.
+func OpenEmpty(t testing.TB) *sql.DB {
+	t.Helper()
+	conn, err := sql.Open("sqlite3", "file::memory:?_fk=true")
+	require.NoError(t, err)
+	// Every connection to file::memory: opens another empty database.
+	conn.SetMaxOpenConns(1)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// Migrations returns the paths of the migrations in the direction, "up" or
+// "down", oldest first.
+func Migrations(t testing.TB, direction string) []string {
+	t.Helper()
+	_, file, _, ok := runtime.Caller(0)
+	require.True(t, ok, "no caller information to find the migrations")
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(file), "..", "migrations", "*."+direction+".sql"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files, "no %s migrations found", direction)
+	sort.Strings(files)
+	return files
+}
+
+// Migrate applies the migration files to the database, in order.
+func Migrate(t testing.TB, conn *sql.DB, files ...string) {
+	t.Helper()
+	for _, m := range files {
+		stmt, err := os.ReadFile(m)
+		require.NoError(t, err)
+		_, err = conn.Exec(string(stmt))
+		require.NoError(t, err, m)
+	}
+}
@@ -0,0 +1,2 @@
+DROP INDEX ledger_entries_account;
+DROP TABLE ledger_entries;
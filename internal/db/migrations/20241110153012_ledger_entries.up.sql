@@ -0,0 +1,15 @@
+CREATE TABLE ledger_entries (
+    id INTEGER PRIMARY KEY,
+    posting_key TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('grant', 'bet', 'payout')),
+    account TEXT NOT NULL,
+    session_id TEXT NOT NULL,
+    game_id INTEGER NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount != 0),
+    created TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (session_id) REFERENCES sessions(id),
+    FOREIGN KEY (game_id) REFERENCES games(id),
+    UNIQUE (posting_key, account)
+) STRICT;
+
+CREATE INDEX ledger_entries_account ON ledger_entries (account);
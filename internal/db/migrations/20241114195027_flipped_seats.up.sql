@@ -0,0 +1 @@
+ALTER TABLE game_sessions ADD COLUMN flipped INTEGER NOT NULL DEFAULT 0 CHECK (flipped IN (0, 1));
@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN variant TEXT NOT NULL DEFAULT 'classic';
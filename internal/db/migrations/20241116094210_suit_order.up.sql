@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN suit_order TEXT NOT NULL DEFAULT '';
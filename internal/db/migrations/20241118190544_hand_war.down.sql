@@ -0,0 +1,6 @@
+ALTER TABLE game_sessions DROP COLUMN commitment;
+ALTER TABLE game_sessions DROP COLUMN nonce;
+ALTER TABLE game_sessions DROP COLUMN choice;
+ALTER TABLE game_sessions DROP COLUMN hand;
+
+ALTER TABLE games DROP COLUMN hand_size;
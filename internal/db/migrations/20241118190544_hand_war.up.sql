@@ -0,0 +1,6 @@
+ALTER TABLE games ADD COLUMN hand_size INTEGER NOT NULL DEFAULT 0 CHECK (hand_size >= 0);
+
+ALTER TABLE game_sessions ADD COLUMN hand TEXT NOT NULL DEFAULT '';
+ALTER TABLE game_sessions ADD COLUMN choice TEXT NOT NULL DEFAULT '';
+ALTER TABLE game_sessions ADD COLUMN nonce TEXT NOT NULL DEFAULT '';
+ALTER TABLE game_sessions ADD COLUMN commitment TEXT NOT NULL DEFAULT '';
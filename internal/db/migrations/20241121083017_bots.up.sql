@@ -0,0 +1 @@
+ALTER TABLE game_sessions ADD COLUMN bot TEXT NOT NULL DEFAULT '';
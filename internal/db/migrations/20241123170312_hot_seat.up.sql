@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN hot_seat INTEGER NOT NULL DEFAULT 0 CHECK (hot_seat IN (0, 1));
@@ -0,0 +1,12 @@
+CREATE TABLE game_rounds (
+    id INTEGER PRIMARY KEY,
+    game_id INTEGER NOT NULL,
+    winner INTEGER NOT NULL CHECK (winner IN (0, 1, 2)),
+    host_card TEXT NOT NULL DEFAULT '',
+    guest_card TEXT NOT NULL DEFAULT '',
+    log TEXT NOT NULL DEFAULT '',
+    created TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (game_id) REFERENCES games(id)
+) STRICT;
+
+CREATE INDEX game_rounds_game_id ON game_rounds (game_id);
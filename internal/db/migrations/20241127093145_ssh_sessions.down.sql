@@ -0,0 +1,3 @@
+DROP INDEX sessions_ssh_fingerprint;
+
+ALTER TABLE sessions DROP COLUMN ssh_fingerprint;
@@ -0,0 +1,3 @@
+ALTER TABLE sessions ADD COLUMN ssh_fingerprint TEXT;
+
+CREATE UNIQUE INDEX sessions_ssh_fingerprint ON sessions (ssh_fingerprint);
@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,14 @@
+CREATE TABLE idempotency_keys (
+    session_id TEXT NOT NULL DEFAULT '',
+    key TEXT NOT NULL,
+    method TEXT NOT NULL,
+    path TEXT NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    header TEXT NOT NULL DEFAULT '',
+    body BLOB NOT NULL DEFAULT x'',
+    expires INTEGER NOT NULL,
+    created TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (session_id, key)
+) STRICT;
+
+CREATE INDEX idempotency_keys_expires ON idempotency_keys (expires);
@@ -0,0 +1,42 @@
+CREATE TEMPORARY TABLE card_codes (slug TEXT PRIMARY KEY, code INTEGER NOT NULL);
+INSERT INTO card_codes (slug, code)
+SELECT v.column1 || s.column1, s.column2 * 16 + v.column2
+FROM (VALUES ('C', 0), ('D', 1), ('H', 2), ('S', 3)) AS s,
+    (VALUES ('2', 2), ('3', 3), ('4', 4), ('5', 5), ('6', 6), ('7', 7), ('8', 8), ('9', 9), ('10', 10),
+        ('J', 11), ('Q', 12), ('K', 13), ('A', 14)) AS v;
+
+-- Cards follow the version byte, from the second byte on.
+CREATE TEMPORARY TABLE session_slugs AS
+WITH RECURSIVE decode(game_id, role, field, cards, pos, slugs) AS (
+    SELECT game_id, role, 'deck', deck, 2, '' FROM game_sessions
+    UNION ALL
+    SELECT game_id, role, 'hand', hand, 2, '' FROM game_sessions
+    UNION ALL
+    SELECT game_id, role, field, cards, pos + 1,
+        slugs || CASE WHEN pos > 2 THEN ',' ELSE '' END
+            || (SELECT slug FROM card_codes WHERE printf('%02X', code) = hex(substr(cards, pos, 1)))
+    FROM decode
+    WHERE pos <= length(cards)
+)
+SELECT game_id, role, field, slugs FROM decode WHERE pos > length(cards);
+
+ALTER TABLE game_sessions ADD COLUMN deck_slugs TEXT NOT NULL DEFAULT '';
+ALTER TABLE game_sessions ADD COLUMN hand_slugs TEXT NOT NULL DEFAULT '';
+
+UPDATE game_sessions SET
+    deck_slugs = (
+        SELECT slugs FROM session_slugs AS s
+        WHERE s.game_id = game_sessions.game_id AND s.role = game_sessions.role AND s.field = 'deck'
+    ),
+    hand_slugs = (
+        SELECT slugs FROM session_slugs AS s
+        WHERE s.game_id = game_sessions.game_id AND s.role = game_sessions.role AND s.field = 'hand'
+    );
+
+ALTER TABLE game_sessions DROP COLUMN deck;
+ALTER TABLE game_sessions DROP COLUMN hand;
+ALTER TABLE game_sessions RENAME COLUMN deck_slugs TO deck;
+ALTER TABLE game_sessions RENAME COLUMN hand_slugs TO hand;
+
+DROP TABLE session_slugs;
+DROP TABLE card_codes;
@@ -0,0 +1,64 @@
+-- Decks and hands were comma-separated card slugs, like '10C,AD'. They become
+-- binary: a version byte, 1, then one byte per card holding the index of its
+-- suit in C, D, H, S times 16, plus its face value from 2 to 14 for an Ace.
+-- unhex needs SQLite 3.41 or later.
+CREATE TEMPORARY TABLE card_codes (slug TEXT PRIMARY KEY, code INTEGER NOT NULL);
+INSERT INTO card_codes (slug, code)
+SELECT v.column1 || s.column1, s.column2 * 16 + v.column2
+FROM (VALUES ('C', 0), ('D', 1), ('H', 2), ('S', 3)) AS s,
+    (VALUES ('2', 2), ('3', 3), ('4', 4), ('5', 5), ('6', 6), ('7', 7), ('8', 8), ('9', 9), ('10', 10),
+        ('J', 11), ('Q', 12), ('K', 13), ('A', 14)) AS v;
+
+-- Each deck and hand is converted to hex one slug at a time, so the cards keep
+-- their order. Each step converts the slug split off by the step before, and
+-- collects it in unknown when it is not a card. Empty slugs are skipped.
+CREATE TEMPORARY TABLE session_codes AS
+WITH RECURSIVE split(game_id, role, field, codes, unknown, slug, rest) AS (
+    SELECT game_id, role, 'deck', '01', '', '', deck || ',' FROM game_sessions
+    UNION ALL
+    SELECT game_id, role, 'hand', '01', '', '', hand || ',' FROM game_sessions
+    UNION ALL
+    SELECT game_id, role, field,
+        codes || COALESCE((SELECT printf('%02X', code) FROM card_codes WHERE card_codes.slug = split.slug), ''),
+        unknown || CASE WHEN slug = '' OR slug IN (SELECT slug FROM card_codes) THEN '' ELSE slug || ',' END,
+        CASE WHEN rest = '' THEN NULL ELSE substr(rest, 1, instr(rest, ',') - 1) END,
+        substr(rest, instr(rest, ',') + 1)
+    FROM split
+    WHERE slug IS NOT NULL
+)
+SELECT game_id, role, field, codes, unknown FROM split WHERE slug IS NULL;
+
+-- Dropping a slug that is not a card would lose a card of the game, so the
+-- migration fails on this constraint instead, and changes nothing. Games
+-- holding such slugs must be fixed or deleted before migrating.
+CREATE TEMPORARY TABLE unknown_slugs (
+    game_id INTEGER NOT NULL,
+    role INTEGER NOT NULL,
+    field TEXT NOT NULL,
+    slugs TEXT NOT NULL,
+    CONSTRAINT decks_and_hands_hold_only_card_slugs CHECK (slugs = '')
+);
+INSERT INTO unknown_slugs (game_id, role, field, slugs)
+SELECT game_id, role, field, unknown FROM session_codes WHERE unknown != '';
+DROP TABLE unknown_slugs;
+
+ALTER TABLE game_sessions ADD COLUMN deck_cards BLOB NOT NULL DEFAULT x'01';
+ALTER TABLE game_sessions ADD COLUMN hand_cards BLOB NOT NULL DEFAULT x'01';
+
+UPDATE game_sessions SET
+    deck_cards = unhex((
+        SELECT codes FROM session_codes AS s
+        WHERE s.game_id = game_sessions.game_id AND s.role = game_sessions.role AND s.field = 'deck'
+    )),
+    hand_cards = unhex((
+        SELECT codes FROM session_codes AS s
+        WHERE s.game_id = game_sessions.game_id AND s.role = game_sessions.role AND s.field = 'hand'
+    ));
+
+ALTER TABLE game_sessions DROP COLUMN deck;
+ALTER TABLE game_sessions DROP COLUMN hand;
+ALTER TABLE game_sessions RENAME COLUMN deck_cards TO deck;
+ALTER TABLE game_sessions RENAME COLUMN hand_cards TO hand;
+
+DROP TABLE session_codes;
+DROP TABLE card_codes;
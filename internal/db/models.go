@@ -9,20 +9,65 @@ import (
 )
 
 type Game struct {
-	ID      int64
-	Code    string
-	Created string
+	ID        int64
+	Code      string
+	Created   string
+	Variant   string
+	SuitOrder string
+	HandSize  int64
+	HotSeat   int64
+	Version   int64
 }
 
-type GameSession struct {
+type GameRound struct {
+	ID        int64
 	GameID    int64
-	SessionID sql.NullString
-	Role      int64
-	Deck      string
+	Winner    int64
+	HostCard  string
+	GuestCard string
+	Log       string
+	Created   string
+}
+
+type GameSession struct {
+	GameID     int64
+	SessionID  sql.NullString
+	Role       int64
+	Created    string
+	Flipped    int64
+	Choice     string
+	Nonce      string
+	Commitment string
+	Bot        string
+	Deck       []byte
+	Hand       []byte
+}
+
+type IdempotencyKey struct {
+	SessionID string
+	Key       string
+	Method    string
+	Path      string
+	Status    int64
+	Header    string
+	Body      []byte
+	Expires   int64
 	Created   string
 }
 
+type LedgerEntry struct {
+	ID         int64
+	PostingKey string
+	Kind       string
+	Account    string
+	SessionID  string
+	GameID     int64
+	Amount     int64
+	Created    string
+}
+
 type Session struct {
-	ID      string
-	Created string
+	ID             string
+	Created        string
+	SshFingerprint sql.NullString
 }
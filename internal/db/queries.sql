@@ -5,18 +5,105 @@ WHERE id = ? LIMIT 1;
 -- name: CreateSession :one
 INSERT INTO sessions (id) VALUES (?) RETURNING id, created;
 
+-- name: GetSessionBySSHFingerprint :one
+SELECT id, created FROM sessions
+WHERE ssh_fingerprint = ? LIMIT 1;
+
+-- name: CreateSSHSession :one
+INSERT INTO sessions (id, ssh_fingerprint) VALUES (?, ?) RETURNING id, created;
+
 -- name: GetGameSessions :many
-SELECT game_id, COALESCE(session_id, ''), role, deck
+SELECT game_id, COALESCE(session_id, ''), role, deck, flipped, hand, choice, nonce, commitment, bot
 FROM game_sessions
 WHERE game_id = ?
 ORDER BY role;
 
 -- name: CreateHostGameSession :exec
-INSERT INTO game_sessions (game_id, session_id, role, deck) VALUES (?, ?, 1, ?), (?, NULL, 2, ?);
+INSERT INTO game_sessions (game_id, session_id, role, deck, hand, bot) VALUES (?, ?, 1, ?, ?, ''), (?, NULL, 2, ?, ?, ?);
+
+-- name: UpdateGameSession :exec
+UPDATE game_sessions SET session_id = ?, deck = ?, flipped = ?, hand = ?, choice = ?, nonce = ?, commitment = ?
+WHERE game_id = ? AND role = ?;
 
 -- name: GetGame :one
-SELECT id, code FROM games
-WHERE id = ? LIMIT 1;
+SELECT games.id, code, variant, suit_order, hand_size, hot_seat, version,
+    (SELECT count(*) FROM game_rounds WHERE game_rounds.game_id = games.id) AS rounds
+FROM games
+WHERE games.id = ? LIMIT 1;
+
+-- name: UpdateGameVersion :execrows
+UPDATE games SET version = version + 1
+WHERE id = ? AND version = ?;
 
 -- name: CreateGame :one
-INSERT INTO games (id) VALUES (NULL) RETURNING id, code;
+INSERT INTO games (variant, suit_order, hand_size, hot_seat) VALUES (?, ?, ?, ?) RETURNING id, code;
+
+-- name: ListGameIDs :many
+SELECT id FROM games ORDER BY id;
+
+-- name: GetGameIDByCode :one
+SELECT id FROM games
+WHERE code = ? LIMIT 1;
+
+-- name: CreateGameRound :exec
+INSERT INTO game_rounds (game_id, winner, host_card, guest_card, log) VALUES (?, ?, ?, ?, ?);
+
+-- name: GetGameRounds :many
+SELECT winner, host_card, guest_card, log FROM game_rounds
+WHERE game_id = ?
+ORDER BY id;
+
+-- name: CreateLedgerEntry :exec
+INSERT INTO ledger_entries (posting_key, kind, account, session_id, game_id, amount) VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetLedgerEntriesByPostingKey :many
+SELECT posting_key, kind, account, session_id, game_id, amount
+FROM ledger_entries
+WHERE posting_key = ?
+ORDER BY id;
+
+-- name: GetLedgerBalance :one
+SELECT CAST(COALESCE(SUM(amount), 0) AS INTEGER) AS balance
+FROM ledger_entries
+WHERE account = ?;
+
+-- name: ListLedgerBalances :many
+SELECT account, CAST(SUM(amount) AS INTEGER) AS balance
+FROM ledger_entries
+GROUP BY account
+ORDER BY account;
+
+-- name: ListUnbalancedLedgerPostings :many
+SELECT posting_key, CAST(SUM(amount) AS INTEGER) AS total
+FROM ledger_entries
+GROUP BY posting_key
+HAVING SUM(amount) != 0
+ORDER BY posting_key;
+
+-- name: ListInconsistentLedgerPostings :many
+SELECT posting_key
+FROM ledger_entries
+GROUP BY posting_key
+HAVING COUNT(DISTINCT kind) > 1 OR COUNT(DISTINCT session_id) > 1 OR COUNT(DISTINCT game_id) > 1 OR COUNT(*) < 2
+ORDER BY posting_key;
+
+-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (session_id, key, method, path, expires) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (session_id, key) DO NOTHING;
+
+-- name: GetIdempotencyKey :one
+SELECT method, path, status, header, body FROM idempotency_keys
+WHERE session_id = ? AND key = ?;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys SET session_id = @new_session_id, status = @status, header = @header, body = @body
+WHERE session_id = @session_id AND key = @key;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys WHERE session_id = ? AND key = ?;
+
+-- name: DeleteIdempotencyKeyIfExpired :exec
+DELETE FROM idempotency_keys WHERE session_id = ? AND key = ? AND expires <= ?;
+
+-- name: DeleteExpiredIdempotencyKeys :exec
+DELETE FROM idempotency_keys WHERE expires <= ?;
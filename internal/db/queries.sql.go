@@ -10,32 +10,96 @@ import (
 	"database/sql"
 )
 
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys SET session_id = ?1, status = ?2, header = ?3, body = ?4
+WHERE session_id = ?5 AND key = ?6
+`
+
+type CompleteIdempotencyKeyParams struct {
+	NewSessionID string
+	Status       int64
+	Header       string
+	Body         []byte
+	SessionID    string
+	Key          string
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, completeIdempotencyKey,
+		arg.NewSessionID,
+		arg.Status,
+		arg.Header,
+		arg.Body,
+		arg.SessionID,
+		arg.Key,
+	)
+	return err
+}
+
 const createGame = `-- name: CreateGame :one
-INSERT INTO games (id) VALUES (NULL) RETURNING id, code
+INSERT INTO games (variant, suit_order, hand_size, hot_seat) VALUES (?, ?, ?, ?) RETURNING id, code
 `
 
+type CreateGameParams struct {
+	Variant   string
+	SuitOrder string
+	HandSize  int64
+	HotSeat   int64
+}
+
 type CreateGameRow struct {
 	ID   int64
 	Code string
 }
 
-func (q *Queries) CreateGame(ctx context.Context) (CreateGameRow, error) {
-	row := q.db.QueryRowContext(ctx, createGame)
+func (q *Queries) CreateGame(ctx context.Context, arg CreateGameParams) (CreateGameRow, error) {
+	row := q.db.QueryRowContext(ctx, createGame,
+		arg.Variant,
+		arg.SuitOrder,
+		arg.HandSize,
+		arg.HotSeat,
+	)
 	var i CreateGameRow
 	err := row.Scan(&i.ID, &i.Code)
 	return i, err
 }
 
+const createGameRound = `-- name: CreateGameRound :exec
+INSERT INTO game_rounds (game_id, winner, host_card, guest_card, log) VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateGameRoundParams struct {
+	GameID    int64
+	Winner    int64
+	HostCard  string
+	GuestCard string
+	Log       string
+}
+
+func (q *Queries) CreateGameRound(ctx context.Context, arg CreateGameRoundParams) error {
+	_, err := q.db.ExecContext(ctx, createGameRound,
+		arg.GameID,
+		arg.Winner,
+		arg.HostCard,
+		arg.GuestCard,
+		arg.Log,
+	)
+	return err
+}
+
 const createHostGameSession = `-- name: CreateHostGameSession :exec
-INSERT INTO game_sessions (game_id, session_id, role, deck) VALUES (?, ?, 1, ?), (?, NULL, 2, ?)
+INSERT INTO game_sessions (game_id, session_id, role, deck, hand, bot) VALUES (?, ?, 1, ?, ?, ''), (?, NULL, 2, ?, ?, ?)
 `
 
 type CreateHostGameSessionParams struct {
 	GameID    int64
 	SessionID sql.NullString
-	Deck      string
+	Deck      []byte
+	Hand      []byte
 	GameID_2  int64
-	Deck_2    string
+	Deck_2    []byte
+	Hand_2    []byte
+	Bot       string
 }
 
 func (q *Queries) CreateHostGameSession(ctx context.Context, arg CreateHostGameSessionParams) error {
@@ -43,52 +107,247 @@ func (q *Queries) CreateHostGameSession(ctx context.Context, arg CreateHostGameS
 		arg.GameID,
 		arg.SessionID,
 		arg.Deck,
+		arg.Hand,
 		arg.GameID_2,
 		arg.Deck_2,
+		arg.Hand_2,
+		arg.Bot,
 	)
 	return err
 }
 
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (session_id, key, method, path, expires) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (session_id, key) DO NOTHING
+`
+
+type CreateIdempotencyKeyParams struct {
+	SessionID string
+	Key       string
+	Method    string
+	Path      string
+	Expires   int64
+}
+
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createIdempotencyKey,
+		arg.SessionID,
+		arg.Key,
+		arg.Method,
+		arg.Path,
+		arg.Expires,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createLedgerEntry = `-- name: CreateLedgerEntry :exec
+INSERT INTO ledger_entries (posting_key, kind, account, session_id, game_id, amount) VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateLedgerEntryParams struct {
+	PostingKey string
+	Kind       string
+	Account    string
+	SessionID  string
+	GameID     int64
+	Amount     int64
+}
+
+func (q *Queries) CreateLedgerEntry(ctx context.Context, arg CreateLedgerEntryParams) error {
+	_, err := q.db.ExecContext(ctx, createLedgerEntry,
+		arg.PostingKey,
+		arg.Kind,
+		arg.Account,
+		arg.SessionID,
+		arg.GameID,
+		arg.Amount,
+	)
+	return err
+}
+
+const createSSHSession = `-- name: CreateSSHSession :one
+INSERT INTO sessions (id, ssh_fingerprint) VALUES (?, ?) RETURNING id, created
+`
+
+type CreateSSHSessionParams struct {
+	ID             string
+	SshFingerprint sql.NullString
+}
+
+type CreateSSHSessionRow struct {
+	ID      string
+	Created string
+}
+
+func (q *Queries) CreateSSHSession(ctx context.Context, arg CreateSSHSessionParams) (CreateSSHSessionRow, error) {
+	row := q.db.QueryRowContext(ctx, createSSHSession, arg.ID, arg.SshFingerprint)
+	var i CreateSSHSessionRow
+	err := row.Scan(&i.ID, &i.Created)
+	return i, err
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO sessions (id) VALUES (?) RETURNING id, created
 `
 
-func (q *Queries) CreateSession(ctx context.Context, id string) (Session, error) {
+type CreateSessionRow struct {
+	ID      string
+	Created string
+}
+
+func (q *Queries) CreateSession(ctx context.Context, id string) (CreateSessionRow, error) {
 	row := q.db.QueryRowContext(ctx, createSession, id)
-	var i Session
+	var i CreateSessionRow
 	err := row.Scan(&i.ID, &i.Created)
 	return i, err
 }
 
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :exec
+DELETE FROM idempotency_keys WHERE expires <= ?
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, expires int64) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredIdempotencyKeys, expires)
+	return err
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys WHERE session_id = ? AND key = ?
+`
+
+type DeleteIdempotencyKeyParams struct {
+	SessionID string
+	Key       string
+}
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKey, arg.SessionID, arg.Key)
+	return err
+}
+
+const deleteIdempotencyKeyIfExpired = `-- name: DeleteIdempotencyKeyIfExpired :exec
+DELETE FROM idempotency_keys WHERE session_id = ? AND key = ? AND expires <= ?
+`
+
+type DeleteIdempotencyKeyIfExpiredParams struct {
+	SessionID string
+	Key       string
+	Expires   int64
+}
+
+func (q *Queries) DeleteIdempotencyKeyIfExpired(ctx context.Context, arg DeleteIdempotencyKeyIfExpiredParams) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKeyIfExpired, arg.SessionID, arg.Key, arg.Expires)
+	return err
+}
+
 const getGame = `-- name: GetGame :one
-SELECT id, code FROM games
-WHERE id = ? LIMIT 1
+SELECT games.id, code, variant, suit_order, hand_size, hot_seat, version,
+    (SELECT count(*) FROM game_rounds WHERE game_rounds.game_id = games.id) AS rounds
+FROM games
+WHERE games.id = ? LIMIT 1
 `
 
 type GetGameRow struct {
-	ID   int64
-	Code string
+	ID        int64
+	Code      string
+	Variant   string
+	SuitOrder string
+	HandSize  int64
+	HotSeat   int64
+	Version   int64
+	Rounds    int64
 }
 
 func (q *Queries) GetGame(ctx context.Context, id int64) (GetGameRow, error) {
 	row := q.db.QueryRowContext(ctx, getGame, id)
 	var i GetGameRow
-	err := row.Scan(&i.ID, &i.Code)
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Variant,
+		&i.SuitOrder,
+		&i.HandSize,
+		&i.HotSeat,
+		&i.Version,
+		&i.Rounds,
+	)
 	return i, err
 }
 
+const getGameIDByCode = `-- name: GetGameIDByCode :one
+SELECT id FROM games
+WHERE code = ? LIMIT 1
+`
+
+func (q *Queries) GetGameIDByCode(ctx context.Context, code string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getGameIDByCode, code)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getGameRounds = `-- name: GetGameRounds :many
+SELECT winner, host_card, guest_card, log FROM game_rounds
+WHERE game_id = ?
+ORDER BY id
+`
+
+type GetGameRoundsRow struct {
+	Winner    int64
+	HostCard  string
+	GuestCard string
+	Log       string
+}
+
+func (q *Queries) GetGameRounds(ctx context.Context, gameID int64) ([]GetGameRoundsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getGameRounds, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGameRoundsRow
+	for rows.Next() {
+		var i GetGameRoundsRow
+		if err := rows.Scan(
+			&i.Winner,
+			&i.HostCard,
+			&i.GuestCard,
+			&i.Log,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getGameSessions = `-- name: GetGameSessions :many
-SELECT game_id, COALESCE(session_id, ''), role, deck
+SELECT game_id, COALESCE(session_id, ''), role, deck, flipped, hand, choice, nonce, commitment, bot
 FROM game_sessions
 WHERE game_id = ?
 ORDER BY role
 `
 
 type GetGameSessionsRow struct {
-	GameID    int64
-	SessionID string
-	Role      int64
-	Deck      string
+	GameID     int64
+	SessionID  string
+	Role       int64
+	Deck       []byte
+	Flipped    int64
+	Hand       []byte
+	Choice     string
+	Nonce      string
+	Commitment string
+	Bot        string
 }
 
 func (q *Queries) GetGameSessions(ctx context.Context, gameID int64) ([]GetGameSessionsRow, error) {
@@ -105,6 +364,102 @@ func (q *Queries) GetGameSessions(ctx context.Context, gameID int64) ([]GetGameS
 			&i.SessionID,
 			&i.Role,
 			&i.Deck,
+			&i.Flipped,
+			&i.Hand,
+			&i.Choice,
+			&i.Nonce,
+			&i.Commitment,
+			&i.Bot,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT method, path, status, header, body FROM idempotency_keys
+WHERE session_id = ? AND key = ?
+`
+
+type GetIdempotencyKeyParams struct {
+	SessionID string
+	Key       string
+}
+
+type GetIdempotencyKeyRow struct {
+	Method string
+	Path   string
+	Status int64
+	Header string
+	Body   []byte
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (GetIdempotencyKeyRow, error) {
+	row := q.db.QueryRowContext(ctx, getIdempotencyKey, arg.SessionID, arg.Key)
+	var i GetIdempotencyKeyRow
+	err := row.Scan(
+		&i.Method,
+		&i.Path,
+		&i.Status,
+		&i.Header,
+		&i.Body,
+	)
+	return i, err
+}
+
+const getLedgerBalance = `-- name: GetLedgerBalance :one
+SELECT CAST(COALESCE(SUM(amount), 0) AS INTEGER) AS balance
+FROM ledger_entries
+WHERE account = ?
+`
+
+func (q *Queries) GetLedgerBalance(ctx context.Context, account string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getLedgerBalance, account)
+	var balance int64
+	err := row.Scan(&balance)
+	return balance, err
+}
+
+const getLedgerEntriesByPostingKey = `-- name: GetLedgerEntriesByPostingKey :many
+SELECT posting_key, kind, account, session_id, game_id, amount
+FROM ledger_entries
+WHERE posting_key = ?
+ORDER BY id
+`
+
+type GetLedgerEntriesByPostingKeyRow struct {
+	PostingKey string
+	Kind       string
+	Account    string
+	SessionID  string
+	GameID     int64
+	Amount     int64
+}
+
+func (q *Queries) GetLedgerEntriesByPostingKey(ctx context.Context, postingKey string) ([]GetLedgerEntriesByPostingKeyRow, error) {
+	rows, err := q.db.QueryContext(ctx, getLedgerEntriesByPostingKey, postingKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLedgerEntriesByPostingKeyRow
+	for rows.Next() {
+		var i GetLedgerEntriesByPostingKeyRow
+		if err := rows.Scan(
+			&i.PostingKey,
+			&i.Kind,
+			&i.Account,
+			&i.SessionID,
+			&i.GameID,
+			&i.Amount,
 		); err != nil {
 			return nil, err
 		}
@@ -124,9 +479,210 @@ SELECT id, created FROM sessions
 WHERE id = ? LIMIT 1
 `
 
-func (q *Queries) GetSession(ctx context.Context, id string) (Session, error) {
+type GetSessionRow struct {
+	ID      string
+	Created string
+}
+
+func (q *Queries) GetSession(ctx context.Context, id string) (GetSessionRow, error) {
 	row := q.db.QueryRowContext(ctx, getSession, id)
-	var i Session
+	var i GetSessionRow
+	err := row.Scan(&i.ID, &i.Created)
+	return i, err
+}
+
+const getSessionBySSHFingerprint = `-- name: GetSessionBySSHFingerprint :one
+SELECT id, created FROM sessions
+WHERE ssh_fingerprint = ? LIMIT 1
+`
+
+type GetSessionBySSHFingerprintRow struct {
+	ID      string
+	Created string
+}
+
+func (q *Queries) GetSessionBySSHFingerprint(ctx context.Context, sshFingerprint sql.NullString) (GetSessionBySSHFingerprintRow, error) {
+	row := q.db.QueryRowContext(ctx, getSessionBySSHFingerprint, sshFingerprint)
+	var i GetSessionBySSHFingerprintRow
 	err := row.Scan(&i.ID, &i.Created)
 	return i, err
 }
+
+const listGameIDs = `-- name: ListGameIDs :many
+SELECT id FROM games ORDER BY id
+`
+
+func (q *Queries) ListGameIDs(ctx context.Context) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listGameIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInconsistentLedgerPostings = `-- name: ListInconsistentLedgerPostings :many
+SELECT posting_key
+FROM ledger_entries
+GROUP BY posting_key
+HAVING COUNT(DISTINCT kind) > 1 OR COUNT(DISTINCT session_id) > 1 OR COUNT(DISTINCT game_id) > 1 OR COUNT(*) < 2
+ORDER BY posting_key
+`
+
+func (q *Queries) ListInconsistentLedgerPostings(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listInconsistentLedgerPostings)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var posting_key string
+		if err := rows.Scan(&posting_key); err != nil {
+			return nil, err
+		}
+		items = append(items, posting_key)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLedgerBalances = `-- name: ListLedgerBalances :many
+SELECT account, CAST(SUM(amount) AS INTEGER) AS balance
+FROM ledger_entries
+GROUP BY account
+ORDER BY account
+`
+
+type ListLedgerBalancesRow struct {
+	Account string
+	Balance int64
+}
+
+func (q *Queries) ListLedgerBalances(ctx context.Context) ([]ListLedgerBalancesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLedgerBalances)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLedgerBalancesRow
+	for rows.Next() {
+		var i ListLedgerBalancesRow
+		if err := rows.Scan(&i.Account, &i.Balance); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnbalancedLedgerPostings = `-- name: ListUnbalancedLedgerPostings :many
+SELECT posting_key, CAST(SUM(amount) AS INTEGER) AS total
+FROM ledger_entries
+GROUP BY posting_key
+HAVING SUM(amount) != 0
+ORDER BY posting_key
+`
+
+type ListUnbalancedLedgerPostingsRow struct {
+	PostingKey string
+	Total      int64
+}
+
+func (q *Queries) ListUnbalancedLedgerPostings(ctx context.Context) ([]ListUnbalancedLedgerPostingsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUnbalancedLedgerPostings)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUnbalancedLedgerPostingsRow
+	for rows.Next() {
+		var i ListUnbalancedLedgerPostingsRow
+		if err := rows.Scan(&i.PostingKey, &i.Total); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateGameSession = `-- name: UpdateGameSession :exec
+UPDATE game_sessions SET session_id = ?, deck = ?, flipped = ?, hand = ?, choice = ?, nonce = ?, commitment = ?
+WHERE game_id = ? AND role = ?
+`
+
+type UpdateGameSessionParams struct {
+	SessionID  sql.NullString
+	Deck       []byte
+	Flipped    int64
+	Hand       []byte
+	Choice     string
+	Nonce      string
+	Commitment string
+	GameID     int64
+	Role       int64
+}
+
+func (q *Queries) UpdateGameSession(ctx context.Context, arg UpdateGameSessionParams) error {
+	_, err := q.db.ExecContext(ctx, updateGameSession,
+		arg.SessionID,
+		arg.Deck,
+		arg.Flipped,
+		arg.Hand,
+		arg.Choice,
+		arg.Nonce,
+		arg.Commitment,
+		arg.GameID,
+		arg.Role,
+	)
+	return err
+}
+
+const updateGameVersion = `-- name: UpdateGameVersion :execrows
+UPDATE games SET version = version + 1
+WHERE id = ? AND version = ?
+`
+
+type UpdateGameVersionParams struct {
+	ID      int64
+	Version int64
+}
+
+func (q *Queries) UpdateGameVersion(ctx context.Context, arg UpdateGameVersionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateGameVersion, arg.ID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,151 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// conflictRetries is the number of times a command is retried after the game
+// was saved by another server.
+const conflictRetries = 3
+
+// command is a change to a game, applied by the game's actor. The update applies
+// the change to a copy of the game and returns the events telling subscribers
+// about it, which are published once the game is saved. A nil update only reads
+// the game.
+type command struct {
+	ctx    context.Context
+	update func(*Game) ([]Event, error)
+	reply  chan result
+}
+
+type result struct {
+	game *Game
+	err  error
+}
+
+// actor owns a game being played. It is the only goroutine changing the game,
+// so commands are applied one at a time, in the order they arrive.
+type actor struct {
+	id       int
+	commands chan command
+	// pending counts the commands sent or about to be sent to the actor, which
+	// must not stop until they are handled. It is guarded by GameService.mu.
+	pending int
+	// game is the game as saved, or nil until it is loaded.
+	game *Game
+}
+
+// do runs the update as a command of the game's actor, starting the actor when
+// the game has none, and returns the game as updated.
+func (s *GameService) do(ctx context.Context, id int, update func(*Game) ([]Event, error)) (*Game, error) {
+	s.mu.Lock()
+	a, ok := s.actors[id]
+	if !ok {
+		a = &actor{id: id, commands: make(chan command)}
+		s.actors[id] = a
+		go s.run(a)
+	}
+	a.pending++
+	s.mu.Unlock()
+
+	cmd := command{ctx: ctx, update: update, reply: make(chan result, 1)}
+	select {
+	case a.commands <- cmd:
+	case <-ctx.Done():
+		s.mu.Lock()
+		a.pending--
+		s.mu.Unlock()
+		return nil, ctx.Err()
+	}
+	r := <-cmd.reply
+	return r.game, r.err
+}
+
+// run handles the actor's commands until it has been idle for the IdleTimeout,
+// or its game fails to load.
+func (s *GameService) run(a *actor) {
+	timer := time.NewTimer(s.IdleTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case cmd := <-a.commands:
+			cmd.reply <- s.handle(a, cmd)
+			s.mu.Lock()
+			a.pending--
+			s.mu.Unlock()
+			if a.game == nil && s.retire(a) {
+				return
+			}
+			timer.Reset(s.IdleTimeout)
+		case <-timer.C:
+			if s.retire(a) {
+				return
+			}
+			timer.Reset(s.IdleTimeout)
+		}
+	}
+}
+
+// retire removes the actor from the service, unless it has pending commands.
+func (s *GameService) retire(a *actor) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a.pending > 0 {
+		return false
+	}
+	delete(s.actors, a.id)
+	return true
+}
+
+// handle applies the command to a copy of the game and saves it. When another
+// server saved the game first, the game is reloaded and the command retried.
+func (s *GameService) handle(a *actor, cmd command) result {
+	if err := cmd.ctx.Err(); err != nil {
+		return result{err: err}
+	}
+	for attempt := 0; ; attempt++ {
+		if a.game == nil {
+			g, err := s.store.GetGame(cmd.ctx, a.id)
+			if err != nil {
+				return result{err: err}
+			}
+			a.game = g
+		}
+		g := a.game.Clone()
+		if cmd.update == nil {
+			return result{game: g}
+		}
+		events, err := cmd.update(g)
+		if err != nil {
+			return result{game: g, err: err}
+		}
+
+		err = s.store.SaveGame(cmd.ctx, g)
+		if errors.Is(err, ErrConflict) && attempt < conflictRetries {
+			s.logger.Info("Reloading game saved by another server",
+				"gameID", a.id,
+				"attempt", attempt+1)
+			a.game = nil
+			continue
+		}
+		if err != nil {
+			return result{err: err}
+		}
+		// The round played is only returned with the game it was played in.
+		a.game = g.Clone()
+		a.game.Battle = nil
+		for _, e := range events {
+			s.events.publish(e)
+		}
+		return result{game: g}
+	}
+}
+
+// ActiveGames returns the number of games with a running actor.
+func (s *GameService) ActiveGames() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.actors)
+}
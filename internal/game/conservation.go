@@ -0,0 +1,75 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var ErrCardsNotConserved = errors.New("cards in play do not form the deck")
+
+// Check returns ErrCardsNotConserved unless the decks together hold exactly the
+// cards of the spec: the right total, with no card duplicated, missing, or from
+// another deck.
+func (s DeckSpec) Check(decks ...Deck) error {
+	counts := make(map[Card]int, s.Size())
+	total := 0
+	for _, d := range decks {
+		for _, c := range d {
+			counts[c]++
+		}
+		total += len(d)
+	}
+
+	problems := make([]string, 0)
+	if total != s.Size() {
+		problems = append(problems, fmt.Sprintf("%d cards instead of %d", total, s.Size()))
+	}
+	var missing, duplicated []string
+	for _, c := range s.Cards() {
+		switch n := counts[c]; {
+		case n == 0:
+			missing = append(missing, c.Slug())
+		case n > 1:
+			duplicated = append(duplicated, fmt.Sprintf("%s x%d", c.Slug(), n))
+		}
+		delete(counts, c)
+	}
+	unexpected := make([]string, 0, len(counts))
+	for c := range counts {
+		unexpected = append(unexpected, c.Slug())
+	}
+	slices.Sort(unexpected)
+	for _, p := range []struct {
+		what  string
+		cards []string
+	}{
+		{"missing", missing},
+		{"duplicated", duplicated},
+		{"not in the deck", unexpected},
+	} {
+		if len(p.cards) > 0 {
+			problems = append(problems, fmt.Sprintf("%s %s", p.what, strings.Join(p.cards, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrCardsNotConserved, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// CheckCards returns ErrCardsNotConserved unless the players together hold
+// exactly the deck the game was dealt from. Rounds are resolved within a move,
+// so between moves every card, including a card chosen but not yet revealed, is
+// in a player's deck or hand.
+func (g *Game) CheckCards() error {
+	decks := make([]Deck, 0, 4)
+	for _, p := range g.Players() {
+		decks = append(decks, p.Deck, p.Hand)
+	}
+	if err := g.Rules.Deck().Check(decks...); err != nil {
+		return fmt.Errorf("gameID '%d': %w", g.ID, err)
+	}
+	return nil
+}
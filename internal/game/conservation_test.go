@@ -0,0 +1,78 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeckSpecCheck(t *testing.T) {
+	spec := DeckSpec{Suits: []Suit{"C", "S"}, Values: []FaceValue{2, 3}}
+	testCases := []struct {
+		scenario string
+		decks    []Deck
+		expected string
+	}{
+		{
+			scenario: "whole deck",
+			decks:    []Deck{{{"C", 2}, {"S", 3}}, {{"C", 3}}, {{"S", 2}}},
+		},
+		{
+			scenario: "missing card",
+			decks:    []Deck{{{"C", 2}, {"S", 3}}, {{"C", 3}}},
+			expected: "3 cards instead of 4; missing 2S",
+		},
+		{
+			scenario: "duplicated card",
+			decks:    []Deck{{{"C", 2}, {"S", 3}}, {{"C", 3}, {"S", 2}, {"C", 2}}},
+			expected: "5 cards instead of 4; duplicated 2C x2",
+		},
+		{
+			scenario: "swapped card",
+			decks:    []Deck{{{"C", 2}, {"S", 3}}, {{"C", 3}, {"C", 3}}},
+			expected: "missing 2S; duplicated 3C x2",
+		},
+		{
+			scenario: "card from another deck",
+			decks:    []Deck{{{"C", 2}, {"S", 3}}, {{"C", 3}, {"H", 2}}},
+			expected: "missing 2S; not in the deck 2H",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			err := spec.Check(tc.decks...)
+			if tc.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrCardsNotConserved)
+			assert.EqualError(t, err, ErrCardsNotConserved.Error()+": "+tc.expected)
+		})
+	}
+}
+
+func TestCheckCardsWhilePlaying(t *testing.T) {
+	for _, rules := range []Rules{
+		{Variant: VariantClassic},
+		{Variant: VariantClassic, HandSize: 3},
+	} {
+		g := NewGame(rules, Seating{}, "host", NewRiffleShuffler())
+		g.Player2.SessionID = "guest"
+		require.NoError(t, g.CheckCards())
+		for i := 0; i < 200 && g.Winner() == nil; i++ {
+			for _, p := range g.Players() {
+				require.NoError(t, g.Play(p, func(g *Game, p *Player) error {
+					if rules.HandSize > 0 {
+						if len(p.Hand) == 0 {
+							return nil
+						}
+						return g.ChooseFor(p, p.Hand[0], "nonce")
+					}
+					return g.FlipFor(p)
+				}))
+				require.NoError(t, g.CheckCards(), "round %d", i)
+			}
+		}
+	}
+}
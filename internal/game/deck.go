@@ -1,10 +1,11 @@
 package game
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand/v2"
-	"strconv"
 	"strings"
 )
 
@@ -95,63 +96,181 @@ func (c Card) Slug() string {
 	return fmt.Sprintf("%s%s", c.Value.Slug(), c.Suit)
 }
 
-// ConvertCardSlug converts a card slug like "10C" or "AD" into the corresponding Card.
-func ConvertCardSlug(s string) (Card, error) {
-	suit := s[len(s)-1:]
-	rawValue := s[:len(s)-1]
-	var value FaceValue
-	switch rawValue {
-	case "A":
-		value = Ace
-	case "J":
-		value = Jack
-	case "Q":
-		value = Queen
-	case "K":
-		value = King
-	default:
-		val, err := strconv.Atoi(rawValue)
-		if err != nil {
-			return Card{}, fmt.Errorf("invalid card slug: %s", s)
-		}
-		value = FaceValue(val)
+var (
+	ErrEmptySlug    = errors.New("card slug is empty")
+	ErrUnknownSuit  = errors.New("unknown suit")
+	ErrUnknownValue = errors.New("unknown face value")
+)
+
+// SlugError describes a card slug that failed to parse.
+type SlugError struct {
+	// Pos is the index of the slug in the deck it was parsed from, or 0 for a
+	// single card.
+	Pos  int
+	Slug string
+	// Err is why the slug is invalid: ErrEmptySlug, ErrUnknownSuit, or
+	// ErrUnknownValue.
+	Err error
+}
+
+func (e *SlugError) Error() string {
+	return fmt.Sprintf("invalid card slug %q at position %d: %v", e.Slug, e.Pos, e.Err)
+}
+
+func (e *SlugError) Unwrap() error {
+	return e.Err
+}
+
+// slugFaceValues maps the slug of every face value in the StandardDeck to the
+// value.
+var slugFaceValues = func() map[string]FaceValue {
+	values := make(map[string]FaceValue, len(StandardDeck.Values))
+	for _, v := range StandardDeck.Values {
+		values[v.Slug()] = v
+	}
+	return values
+}()
+
+// ParseCard parses a card slug like "10C" or "AD": the slug of a face value from
+// 2 to Ace, then an upper case suit. It returns a *SlugError for anything else.
+func ParseCard(s string) (Card, error) {
+	return parseCard(0, s)
+}
+
+func parseCard(pos int, s string) (Card, error) {
+	fail := func(err error) (Card, error) {
+		return Card{}, &SlugError{Pos: pos, Slug: s, Err: err}
+	}
+	if s == "" {
+		return fail(ErrEmptySlug)
+	}
+	suit := Suit(s[len(s)-1:])
+	if _, ok := SuitNames[suit]; !ok {
+		return fail(ErrUnknownSuit)
 	}
-	return Card{Suit: Suit(suit), Value: FaceValue(value)}, nil
+	value, ok := slugFaceValues[s[:len(s)-1]]
+	if !ok {
+		return fail(ErrUnknownValue)
+	}
+	return Card{Suit: suit, Value: value}, nil
+}
+
+// MarshalText returns the card's slug, or a *SlugError when the card is not in
+// the StandardDeck. Cards are encoded in JSON as their slug too.
+func (c Card) MarshalText() ([]byte, error) {
+	if _, err := ParseCard(c.Slug()); err != nil {
+		return nil, err
+	}
+	return []byte(c.Slug()), nil
+}
+
+// UnmarshalText parses the card's slug with ParseCard.
+func (c *Card) UnmarshalText(text []byte) error {
+	card, err := ParseCard(string(text))
+	if err != nil {
+		return err
+	}
+	*c = card
+	return nil
 }
 
 type Deck []Card
 
-func NewDeck() Deck {
-	d := make([]Card, 0)
-	for _, s := range []Suit{SuitClub, SuitDiamond, SuitHeart, SuitSpade} {
-		var v FaceValue
-		for v = 2; v <= Ace; v++ {
-			d = append(d, Card{Suit: s, Value: v})
+// DeckSpec describes a deck holding one card of each value in each suit.
+type DeckSpec struct {
+	Suits  []Suit
+	Values []FaceValue
+}
+
+// StandardDeck is the 52 card deck every game is dealt from.
+var StandardDeck = DeckSpec{
+	Suits:  []Suit{SuitClub, SuitDiamond, SuitHeart, SuitSpade},
+	Values: []FaceValue{2, 3, 4, 5, 6, 7, 8, 9, 10, Jack, Queen, King, Ace},
+}
+
+// Size returns the number of cards in the deck.
+func (s DeckSpec) Size() int {
+	return len(s.Suits) * len(s.Values)
+}
+
+// Cards returns every card in the deck, by suit and then by value.
+func (s DeckSpec) Cards() Deck {
+	d := make(Deck, 0, s.Size())
+	for _, suit := range s.Suits {
+		for _, v := range s.Values {
+			d = append(d, Card{Suit: suit, Value: v})
 		}
 	}
 	return d
 }
 
-// ConvertDeck converts a comma-separated string of card slugs into a Deck.
-func ConvertDeck(s string) Deck {
+func NewDeck() Deck {
+	return StandardDeck.Cards()
+}
+
+// ParseDeck parses a comma-separated string of card slugs, as returned by
+// Deck.String, with ParseCard. An empty string is an empty Deck. It returns a
+// *SlugError for the first invalid slug, including empty ones.
+func ParseDeck(s string) (Deck, error) {
+	if s == "" {
+		return Deck{}, nil
+	}
 	slugs := strings.Split(s, ",")
-	d := make([]Card, 0)
-	for _, slug := range slugs {
+	d := make(Deck, len(slugs))
+	for i, slug := range slugs {
+		card, err := parseCard(i, slug)
+		if err != nil {
+			return nil, err
+		}
+		d[i] = card
+	}
+	return d, nil
+}
+
+// ParseDeckLenient parses a comma-separated string of card slugs like ParseDeck,
+// but skips empty slugs, and the slugs that fail to parse, which it returns as
+// *SlugErrors. It suits input where dropping a card is better than failing,
+// which game state never is.
+func ParseDeckLenient(s string) (Deck, []*SlugError) {
+	d := make(Deck, 0)
+	var skipped []*SlugError
+	for i, slug := range strings.Split(s, ",") {
 		if slug == "" {
 			continue
 		}
-		card, err := ConvertCardSlug(slug)
+		card, err := parseCard(i, slug)
 		if err != nil {
-			log.Printf("invalid card slug: %s", slug)
+			var slugErr *SlugError
+			if errors.As(err, &slugErr) {
+				skipped = append(skipped, slugErr)
+			}
 			continue
 		}
 		d = append(d, card)
 	}
+	return d, skipped
+}
+
+// ConvertCardSlug converts a card slug like "10C" or "AD" into the corresponding Card.
+//
+// Deprecated: Use ParseCard, which ConvertCardSlug now calls. It also rejects
+// cards that are not in the StandardDeck.
+func ConvertCardSlug(s string) (Card, error) {
+	return ParseCard(s)
+}
+
+// ConvertDeck converts a comma-separated string of card slugs into a Deck,
+// skipping invalid slugs.
+//
+// Deprecated: Use ParseDeck, or ParseDeckLenient to keep skipping invalid slugs
+// and learn which ones were skipped.
+func ConvertDeck(s string) Deck {
+	d, _ := ParseDeckLenient(s)
 	return d
 }
 
 // Cut returns 2 new decks, each containing exactly 1/2 of the original deck, with
-// the extra card (in odd-sized decks) added to the first (left) deck.
+// the extra card (in odd-sized decks) added to the second (right) deck.
 func (d Deck) Cut() (Deck, Deck) {
 	left, right := make(Deck, 0), make(Deck, 0)
 	for i := 0; i < len(d); i++ {
@@ -174,6 +293,65 @@ func (d Deck) String() string {
 	return strings.Join(r, ",")
 }
 
+// slugs returns the slug of every card in the deck, or a *SlugError for the
+// first card that is not in the StandardDeck.
+func (d Deck) slugs() ([]string, error) {
+	slugs := make([]string, len(d))
+	for i, c := range d {
+		if _, err := parseCard(i, c.Slug()); err != nil {
+			return nil, err
+		}
+		slugs[i] = c.Slug()
+	}
+	return slugs, nil
+}
+
+// MarshalText returns the deck as a comma-separated string of card slugs.
+func (d Deck) MarshalText() ([]byte, error) {
+	slugs, err := d.slugs()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.Join(slugs, ",")), nil
+}
+
+// UnmarshalText parses a comma-separated string of card slugs with ParseDeck.
+func (d *Deck) UnmarshalText(text []byte) error {
+	deck, err := ParseDeck(string(text))
+	if err != nil {
+		return err
+	}
+	*d = deck
+	return nil
+}
+
+// MarshalJSON returns the deck as a JSON array of card slugs.
+func (d Deck) MarshalJSON() ([]byte, error) {
+	slugs, err := d.slugs()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(slugs)
+}
+
+// UnmarshalJSON parses a JSON array of card slugs with ParseCard.
+func (d *Deck) UnmarshalJSON(data []byte) error {
+	var slugs []string
+	if err := json.Unmarshal(data, &slugs); err != nil {
+		return err
+	}
+	deck := make(Deck, len(slugs))
+	for i, slug := range slugs {
+		card, err := parseCard(i, slug)
+		if err != nil {
+			return err
+		}
+		deck[i] = card
+	}
+	*d = deck
+	return nil
+}
+
 type Shuffler interface {
 	Shuffle(Deck) Deck
 }
@@ -1,9 +1,11 @@
 package game
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCardName(t *testing.T) {
@@ -344,28 +346,140 @@ func TestDeckString(t *testing.T) {
 	}
 }
 
-func TestConvertDeck(t *testing.T) {
+func TestParseCard(t *testing.T) {
+	testCases := []struct {
+		slug     string
+		expected Card
+		err      error
+	}{
+		{slug: "10C", expected: Card{"C", 10}},
+		{slug: "AD", expected: Card{"D", Ace}},
+		{slug: "2S", expected: Card{"S", 2}},
+		{slug: "", err: ErrEmptySlug},
+		{slug: "C", err: ErrUnknownValue},
+		{slug: "2X", err: ErrUnknownSuit},
+		{slug: "2c", err: ErrUnknownSuit},
+		{slug: "1H", err: ErrUnknownValue},
+		{slug: "99H", err: ErrUnknownValue},
+		{slug: "02H", err: ErrUnknownValue},
+		{slug: "aH", err: ErrUnknownValue},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.slug, func(t *testing.T) {
+			c, err := ParseCard(tc.slug)
+			if tc.err == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, c)
+				return
+			}
+			assert.ErrorIs(t, err, tc.err)
+			var slugErr *SlugError
+			require.ErrorAs(t, err, &slugErr)
+			assert.Equal(t, tc.slug, slugErr.Slug)
+		})
+	}
+}
+
+func TestParseDeck(t *testing.T) {
 	testCases := []struct {
 		slug     string
 		expected Deck
+		lenient  Deck
+		skipped  []int
+		pos      int
+		err      error
 	}{
 		{
 			slug:     "",
 			expected: Deck{},
+			lenient:  Deck{},
 		},
 		{
 			slug:     "10C,AD",
 			expected: Deck{Card{"C", 10}, Card{"D", Ace}},
+			lenient:  Deck{Card{"C", 10}, Card{"D", Ace}},
 		},
 		{
 			slug:     "10C,AD,3H",
 			expected: Deck{Card{"C", 10}, Card{"D", Ace}, Card{"H", 3}},
+			lenient:  Deck{Card{"C", 10}, Card{"D", Ace}, Card{"H", 3}},
+		},
+		{
+			slug:    "10C,1X,3H",
+			lenient: Deck{Card{"C", 10}, Card{"H", 3}},
+			skipped: []int{1},
+			pos:     1,
+			err:     ErrUnknownSuit,
+		},
+		{
+			slug:    "10C,AD,",
+			lenient: Deck{Card{"C", 10}, Card{"D", Ace}},
+			pos:     2,
+			err:     ErrEmptySlug,
 		},
 	}
 
-	for _, c := range testCases {
-		t.Run("convert deck", func(t *testing.T) {
-			assert.Equal(t, ConvertDeck(c.slug), c.expected)
+	for _, tc := range testCases {
+		t.Run(tc.slug, func(t *testing.T) {
+			lenient, skipped := ParseDeckLenient(tc.slug)
+			assert.Equal(t, tc.lenient, lenient)
+			require.Len(t, skipped, len(tc.skipped))
+			for i, pos := range tc.skipped {
+				assert.Equal(t, pos, skipped[i].Pos)
+			}
+			assert.Equal(t, tc.lenient, ConvertDeck(tc.slug))
+
+			d, err := ParseDeck(tc.slug)
+			if tc.err == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, d)
+				assert.Equal(t, tc.slug, d.String())
+				return
+			}
+			assert.ErrorIs(t, err, tc.err)
+			var slugErr *SlugError
+			require.ErrorAs(t, err, &slugErr)
+			assert.Equal(t, tc.pos, slugErr.Pos)
 		})
 	}
 }
+
+func TestCardMarshaling(t *testing.T) {
+	c := Card{SuitClub, 10}
+	data, err := json.Marshal(map[string]Card{"host": c})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"host": "10C"}`, string(data))
+
+	var decoded map[string]Card
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, c, decoded["host"])
+
+	_, err = Card{"X", 1}.MarshalText()
+	assert.ErrorIs(t, err, ErrUnknownSuit)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`"1C"`), &c), ErrUnknownValue)
+}
+
+func TestDeckMarshaling(t *testing.T) {
+	d := Deck{{SuitHeart, 2}, {SuitSpade, Ace}}
+	text, err := d.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "2H,AS", string(text))
+	var fromText Deck
+	require.NoError(t, fromText.UnmarshalText(text))
+	assert.Equal(t, d, fromText)
+
+	data, err := json.Marshal(d)
+	require.NoError(t, err)
+	assert.JSONEq(t, `["2H", "AS"]`, string(data))
+	var fromJSON Deck
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	assert.Equal(t, d, fromJSON)
+
+	_, err = json.Marshal(Deck{{SuitHeart, 2}, {"X", 2}})
+	assert.ErrorIs(t, err, ErrUnknownSuit)
+	err = json.Unmarshal([]byte(`["2H", "2H", "15H"]`), &fromJSON)
+	var slugErr *SlugError
+	require.ErrorAs(t, err, &slugErr)
+	assert.Equal(t, 2, slugErr.Pos)
+	assert.Equal(t, "15H", slugErr.Slug)
+}
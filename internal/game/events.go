@@ -0,0 +1,74 @@
+package game
+
+import "sync"
+
+// EventType names what changed in a game.
+type EventType string
+
+const (
+	EventJoined EventType = "joined"
+	EventMoved  EventType = "moved"
+	EventRound  EventType = "round"
+)
+
+// Event tells subscribers that a game changed. It only carries what every player
+// may see, so subscribers load the game to see their own hand.
+type Event struct {
+	Type   EventType
+	GameID int
+	// Role is the seat that joined or moved.
+	Role GameRole
+	// Battle is the round played, for EventRound.
+	Battle *Battle
+}
+
+// subscriptionBuffer is the number of events a subscriber may fall behind by
+// before further events are dropped for it.
+const subscriptionBuffer = 16
+
+type broker struct {
+	mu   sync.Mutex
+	subs map[int]map[chan Event]struct{}
+}
+
+func newBroker() *broker {
+	return &broker{subs: make(map[int]map[chan Event]struct{})}
+}
+
+// subscribe returns a channel receiving every Event published for the game, and a
+// function that cancels the subscription and closes the channel.
+func (b *broker) subscribe(gameID int) (<-chan Event, func()) {
+	ch := make(chan Event, subscriptionBuffer)
+	b.mu.Lock()
+	if b.subs[gameID] == nil {
+		b.subs[gameID] = make(map[chan Event]struct{})
+	}
+	b.subs[gameID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subs[gameID], ch)
+			if len(b.subs[gameID]) == 0 {
+				delete(b.subs, gameID)
+			}
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+// publish sends the event to every subscriber of its game without blocking.
+func (b *broker) publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs[e.GameID] {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func FuzzParseCard(f *testing.F) {
+	for _, slug := range []string{"10C", "AD", "2S", "", "C", "2X", "1H", "99H", "02H", "10", "\xff"} {
+		f.Add(slug)
+	}
+	f.Fuzz(func(t *testing.T, slug string) {
+		c, err := ParseCard(slug)
+		if err != nil {
+			var slugErr *SlugError
+			require.ErrorAs(t, err, &slugErr)
+			assert.Equal(t, slug, slugErr.Slug)
+			assert.True(t, errors.Is(err, ErrEmptySlug) || errors.Is(err, ErrUnknownSuit) || errors.Is(err, ErrUnknownValue), err)
+			return
+		}
+		assert.Equal(t, slug, c.Slug())
+		assert.Contains(t, StandardDeck.Cards(), c)
+		text, err := c.MarshalText()
+		require.NoError(t, err)
+		assert.Equal(t, slug, string(text))
+	})
+}
+
+func FuzzParseDeck(f *testing.F) {
+	for _, s := range []string{"", "10C,AD", "10C,AD,3H", "10C,1X,3H", "10C,AD,", ",", "AS,AS"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		d, err := ParseDeck(s)
+		if err != nil {
+			var slugErr *SlugError
+			require.ErrorAs(t, err, &slugErr)
+			slugs := strings.Split(s, ",")
+			require.Less(t, slugErr.Pos, len(slugs))
+			assert.Equal(t, slugs[slugErr.Pos], slugErr.Slug)
+			return
+		}
+		assert.Equal(t, s, d.String())
+
+		text, err := d.MarshalText()
+		require.NoError(t, err)
+		assert.Equal(t, s, string(text))
+		data, err := json.Marshal(d)
+		require.NoError(t, err)
+		var decoded Deck
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, d, decoded)
+	})
+}
+
+func FuzzParseDeckLenient(f *testing.F) {
+	for _, s := range []string{"", "10C,AD", "10C,1X,3H", "10C,AD,", ",,", "1X,,AS,2Z"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		d, skipped := ParseDeckLenient(s)
+		slugs := strings.Split(s, ",")
+		kept := make([]string, 0, len(slugs))
+		next := 0
+		for i, slug := range slugs {
+			if next < len(skipped) && skipped[next].Pos == i {
+				assert.Equal(t, slug, skipped[next].Slug)
+				next++
+				continue
+			}
+			if slug != "" {
+				kept = append(kept, slug)
+			}
+		}
+		require.Equal(t, len(skipped), next, "skipped slugs out of order: %v", skipped)
+		for _, slugErr := range skipped {
+			assert.NotErrorIs(t, slugErr, ErrEmptySlug)
+		}
+
+		// The cards kept are the valid slugs, in order.
+		strict, err := ParseDeck(strings.Join(kept, ","))
+		require.NoError(t, err)
+		assert.Equal(t, strict, d)
+	})
+}
+
+func FuzzDeckString(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 51})
+	f.Add([]byte{8, 8, 8})
+	f.Add([]byte{255, 12, 100})
+	cards := StandardDeck.Cards()
+	f.Fuzz(func(t *testing.T, picks []byte) {
+		d := make(Deck, len(picks))
+		for i, p := range picks {
+			d[i] = cards[int(p)%len(cards)]
+		}
+		parsed, err := ParseDeck(d.String())
+		require.NoError(t, err)
+		assert.True(t, slices.Equal(d, parsed), "%s round-tripped to %s", d, parsed)
+
+		var fromText Deck
+		text, err := d.MarshalText()
+		require.NoError(t, err)
+		require.NoError(t, fromText.UnmarshalText(text))
+		assert.True(t, slices.Equal(d, fromText))
+
+		var fromBinary Deck
+		data, err := d.MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, fromBinary.UnmarshalBinary(data))
+		assert.True(t, slices.Equal(d, fromBinary))
+	})
+}
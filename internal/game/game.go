@@ -1,21 +1,25 @@
+// Package game implements the rules of War. It only depends on the standard
+// library, so the same engine also runs in the browser as WebAssembly.
 package game
 
 import (
-	"database/sql"
+	"errors"
 	"fmt"
-	"html/template"
-	"net/http"
-	"path/filepath"
-	"strconv"
-
-	"github.com/seanjh/war/internal/appcontext"
-	"github.com/seanjh/war/internal/db"
-	"github.com/seanjh/war/internal/session"
+	"slices"
 )
 
 type Player struct {
-	Deck Deck
-	Role GameRole
+	Deck      Deck
+	Role      GameRole
+	SessionID string
+	Flipped   bool
+	// Hand holds the cards the player may choose from in the hand War variant.
+	Hand Deck
+	// Commitment is set once the player has secretly chosen a card from Hand.
+	Commitment Commitment
+	choice     *Choice
+	// Bot names the Autoplayer that owns the seat, when it is not owned by a session.
+	Bot string
 }
 
 type GameRole int64
@@ -36,6 +40,17 @@ func (r GameRole) String() string {
 	return "unknown"
 }
 
+// ParseGameRole returns the role named by s, or Unknown.
+func ParseGameRole(s string) GameRole {
+	switch s {
+	case Host.String():
+		return Host
+	case Guest.String():
+		return Guest
+	}
+	return Unknown
+}
+
 func ConvertGameRole(val int64) GameRole {
 	if val > int64(Guest) {
 		return Unknown
@@ -46,224 +61,128 @@ func ConvertGameRole(val int64) GameRole {
 type Battle struct {
 	Battle map[string]Card
 	War    map[string][]Card
+	Winner GameRole
+	// Reveals holds the card and nonce behind each player's Commitment in the
+	// hand War variant.
+	Reveals map[string]Choice
+	// Log describes each step of the round in the order it was played.
+	Log []string
 }
 
 type Game struct {
 	ID      int
+	Code    string
+	Rules   Rules
 	Player1 *Player
 	Player2 *Player
 	Battle  *Battle
+	// HotSeat is true when the host session controls both seats, for two players
+	// sharing one device.
+	HotSeat bool
+	// Version counts the updates saved to the game, so stores can detect updates
+	// made concurrently.
+	Version int
+	// Rounds counts the rounds played.
+	Rounds int
 }
 
-// OpenNewGame returns a new Game with 2 Players with equal cuts of a new Deck.
-func OpenNewGame(r *http.Request, sessionID string) (*Game, error) {
-	ctx := appcontext.GetAppContext(r)
-
-	tx, err := ctx.DBWriter.DB.Begin()
-	defer tx.Rollback()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create new game: %w", err)
-	}
-
-	gameRow, err := ctx.DBWriter.Query.WithTx(tx).CreateGame(r.Context())
-	if err != nil {
-		return nil, fmt.Errorf("failed to create new game: %w", err)
-	}
-	ctx.Logger.Info("Created new game row",
-		"gameID", gameRow.ID,
-		"gameCode", gameRow.Code)
-
-	deck := NewDeck()
-	deck.Shuffle(NewRiffleShuffler())
-	d1, d2 := deck.Cut()
-
-	err = ctx.DBWriter.Query.WithTx(tx).CreateHostGameSession(r.Context(), db.CreateHostGameSessionParams{
-		GameID:    gameRow.ID,
-		GameID_2:  gameRow.ID,
-		Deck:      d1.String(),
-		Deck_2:    d2.String(),
-		SessionID: sql.NullString{String: sessionID, Valid: true},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create new host game session: %w", err)
-	}
-	err = tx.Commit()
-	if err != nil {
-		return nil, fmt.Errorf("failed to commit new host game session: %w", err)
-	}
-
-	game := &Game{
-		ID:      int(gameRow.ID),
-		Player1: &Player{Deck: d1, Role: Host},
-		Player2: &Player{Deck: d2, Role: Guest},
-		Battle:  &Battle{},
-	}
-	return game, nil
+// Clone returns a copy of the game that shares nothing with it that a move
+// changes. The Battle is shared, since it is not changed once played.
+func (g *Game) Clone() *Game {
+	c := *g
+	c.Player1 = g.Player1.clone()
+	c.Player2 = g.Player2.clone()
+	return &c
 }
 
-// LoadGame returns a pre-existing Game when recognized.
-func LoadGame(rawGameID string, r *http.Request) (*Game, error) {
-	gameID, err := strconv.Atoi(rawGameID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to convert gameID '%s' to int: %w", rawGameID, err)
+func (p *Player) clone() *Player {
+	if p == nil {
+		return nil
 	}
+	c := *p
+	c.Deck = slices.Clone(p.Deck)
+	c.Hand = slices.Clone(p.Hand)
+	return &c
+}
 
-	game := &Game{ID: gameID}
-
-	ctx := appcontext.GetAppContext(r)
-	sess := session.GetSession(r)
-	rows, err := ctx.DBReader.Query.GetGameSessions(r.Context(), int64(gameID))
-	if err != nil {
-		return nil, fmt.Errorf("failed to load gameID '%d' from database: %w", gameID, err)
-	}
-	for _, row := range rows {
-		role := ConvertGameRole(row.Role)
-		deck := ConvertDeck(row.Deck)
-		switch role {
-		case Host:
-			game.Player1 = &Player{Role: Host, Deck: deck}
-		case Guest:
-			game.Player2 = &Player{Role: Guest, Deck: deck}
-		default:
-			ctx.Logger.Error("Unsupported player role",
-				"sessionID", sess.ID,
-				"row", row)
+// Players returns the seated players in role order.
+func (g *Game) Players() []*Player {
+	players := make([]*Player, 0, 2)
+	for _, p := range []*Player{g.Player1, g.Player2} {
+		if p != nil {
+			players = append(players, p)
 		}
 	}
-	return game, nil
+	return players
 }
 
-type PlayerContext struct {
-	GameID int
-	Player *Player
+// MaxRounds is the number of rounds after which a game without a winner is drawn.
+// Classic War can otherwise cycle forever.
+const MaxRounds = 5000
+
+// Winner returns the only player with cards left once the game is over, or nil
+// while the game is still being played or was drawn.
+func (g *Game) Winner() *Player {
+	var winner *Player
+	for _, p := range g.Players() {
+		if p.CardCount() == 0 {
+			continue
+		}
+		if winner != nil {
+			return nil
+		}
+		winner = p
+	}
+	return winner
 }
 
-type GameContext struct {
-	Player1 PlayerContext
-	Player2 PlayerContext
+// Drawn reports whether the game ended without a winner after MaxRounds rounds.
+func (g *Game) Drawn() bool {
+	return g.Rounds >= MaxRounds && g.Winner() == nil
 }
 
-func loadGameTemplates() *template.Template {
-	return template.Must(template.ParseFiles(
-		filepath.Join("templates", "layout.html"),
-		filepath.Join("templates", "game.html"),
-		filepath.Join("templates", "player.html"),
-		filepath.Join("templates", "battleground.html"),
-		filepath.Join("templates", "warzone.html"),
-	))
+// Over reports whether the game was won or drawn.
+func (g *Game) Over() bool {
+	return g.Winner() != nil || g.Drawn()
 }
 
-func CreateAndRenderGame() http.HandlerFunc {
-	tmpl := loadGameTemplates()
-	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := appcontext.GetAppContext(r)
-
-		s := session.GetSession(r)
-		if s.ID == "" {
-			newSession, err := session.OpenNewSession(w, r)
-			s = newSession
-			if err != nil {
-				ctx.Logger.Info("Failed to open new session",
-					"err", err,
-				)
-				http.Error(w, "Failed to create new session", http.StatusInternalServerError)
-				return
-			}
-		}
-
-		game, err := OpenNewGame(r, s.ID)
-		if err != nil {
-			ctx.Logger.Error("Failed to create new game", "err", err)
-			http.Error(w, "Failed to create new game", http.StatusInternalServerError)
-			return
-		}
-		ctx.Logger.Info("Created new game and host game session",
-			"gameID", game.ID,
-		)
-		w.Header().Add("hx-push-url", fmt.Sprintf("/game/%d", game.ID))
-
-		data := GameContext{
-			Player1: PlayerContext{GameID: game.ID, Player: game.Player1},
-			Player2: PlayerContext{GameID: game.ID, Player: game.Player2},
-		}
-		if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
-			ctx.Logger.Error("Failed to render game template",
-				"err", err,
-				"gameID", game.ID,
-				"sessionID", s.ID)
-			http.Error(w, "Failed to render game", http.StatusInternalServerError)
-			return
-		}
-	}
+// Seating describes who controls the guest seat of a new game.
+type Seating struct {
+	// GuestBot names the Autoplayer that owns the guest seat, when not empty.
+	GuestBot string
+	// HotSeat gives the host session control of the guest seat too.
+	HotSeat bool
 }
 
-func RenderGame() http.HandlerFunc {
-	tmpl := loadGameTemplates()
-	return func(w http.ResponseWriter, r *http.Request) {
-		id := r.PathValue("id")
-		ctx := appcontext.GetAppContext(r)
-
-		s := session.GetSession(r)
-		if s.ID == "" {
-			ctx.Logger.Error("missing required session for game",
-				"gameID", id,
-				"sessionID", s.ID)
-			http.Error(w, "cannot locate game", http.StatusBadRequest)
-			return
-		}
-
-		game, err := LoadGame(id, r)
-		if err != nil {
-			ctx.Logger.Error("failed to load game from database",
-				"err", err,
-				"sessionID", s.ID,
-				"gameID", id)
-			http.Error(w, "cannot locate game", http.StatusBadRequest)
-			return
-		}
+var ErrInvalidSeating = errors.New("seating is not allowed")
 
-		data := GameContext{
-			Player1: PlayerContext{GameID: game.ID, Player: game.Player1},
-			Player2: PlayerContext{GameID: game.ID, Player: game.Player2},
-		}
-		err = tmpl.ExecuteTemplate(w, "layout", data)
-		if err != nil {
-			ctx.Logger.Error("ExecuteTemplate failed",
-				"err", err,
-				"gameID", game.ID,
-				"sessionID", s.ID)
-			http.Error(w, "failed to load game", http.StatusInternalServerError)
-			return
-		}
+// NewGame deals a new game with the rules, giving each seat an equal cut of a new
+// deck shuffled by the shuffler. The host seat is owned by the session, and the
+// guest seat is controlled as described by seating.
+func NewGame(rules Rules, seating Seating, sessionID string, s Shuffler) *Game {
+	deck := rules.Deck().Cards()
+	deck.Shuffle(s)
+	d1, d2 := deck.Cut()
+	host := &Player{Deck: d1, Role: Host, SessionID: sessionID}
+	guest := &Player{Deck: d2, Role: Guest, Bot: seating.GuestBot}
+	host.Deal(rules.HandSize)
+	guest.Deal(rules.HandSize)
+	return &Game{
+		Rules:   rules,
+		Player1: host,
+		Player2: guest,
+		HotSeat: seating.HotSeat,
 	}
 }
 
-func CreateFlip() http.HandlerFunc {
-	// tmpl := template.Must(template.ParseFiles(
-	// 	filepath.Join("templates", "game.html"),
-	// 	filepath.Join("templates", "player.html"),
-	// 	filepath.Join("templates", "battleground.html"),
-	// 	filepath.Join("templates", "warzone.html"),
-	// ))
-	return func(w http.ResponseWriter, r *http.Request) {
-		http.Error(w, "Not implemented", http.StatusInternalServerError)
+// Validate checks that the guest bot is registered, and that a bot does not take
+// the guest seat of a hot-seat game.
+func (s Seating) Validate() error {
+	if s.GuestBot != "" && !IsAutoplayer(s.GuestBot) {
+		return fmt.Errorf("%w: unknown opponent '%s'", ErrInvalidSeating, s.GuestBot)
 	}
-}
-
-func RenderHome() http.HandlerFunc {
-	tmpl := template.Must(template.ParseFiles(
-		filepath.Join("templates", "layout.html"),
-		filepath.Join("templates", "home.html"),
-	))
-	return func(w http.ResponseWriter, r *http.Request) {
-		tmpl.ExecuteTemplate(w, "layout", nil)
+	if s.GuestBot != "" && s.HotSeat {
+		return fmt.Errorf("%w: a hot-seat game cannot have a computer opponent", ErrInvalidSeating)
 	}
-}
-
-func SetupRoutes(mux *http.ServeMux) *http.ServeMux {
-	mux.Handle("GET /", http.HandlerFunc(RenderHome()))
-	mux.Handle("POST /game", session.WithSessionMiddleware(CreateAndRenderGame()))
-	mux.Handle("GET /game/{id}", session.WithSessionMiddleware(RenderGame()))
-	mux.Handle("POST /game/{id}/flip", session.WithSessionMiddleware(CreateFlip()))
-	return mux
+	return nil
 }
@@ -0,0 +1,187 @@
+package game
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// MaxHandSize is the largest hand allowed in the hand War variant.
+const MaxHandSize = 5
+
+// Commitment binds a player to a hidden card choice. It is the hex-encoded SHA-256
+// of the chosen card slug and a random nonce, so it can be shown to the other
+// players without revealing the card, and checked once the card is revealed.
+type Commitment string
+
+// Commit returns the Commitment for playing the card with the nonce.
+func Commit(c Card, nonce string) Commitment {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%s", c.Slug(), nonce)))
+	return Commitment(hex.EncodeToString(sum[:]))
+}
+
+// Verify reports whether the revealed card and nonce match the Commitment.
+func (c Commitment) Verify(card Card, nonce string) bool {
+	return c == Commit(card, nonce)
+}
+
+// Short returns an abbreviated Commitment for display.
+func (c Commitment) Short() string {
+	if len(c) < 8 {
+		return string(c)
+	}
+	return string(c[:8])
+}
+
+const nonceNumBytes = 16
+
+// NewNonce returns a random nonce for a Commitment.
+func NewNonce() (string, error) {
+	bytes := make([]byte, nonceNumBytes)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// Choice is a card chosen from a player's hand, which is kept secret until every
+// player has chosen.
+type Choice struct {
+	Card  Card
+	Nonce string
+}
+
+var (
+	ErrNotInHand          = errors.New("card is not in the player's hand")
+	ErrAlreadyChosen      = errors.New("player already chose a card this round")
+	ErrNoChoice           = errors.New("player has not chosen a card")
+	ErrCommitmentMismatch = errors.New("revealed card does not match the commitment")
+)
+
+// CardCount returns the number of cards the player holds in their deck and hand.
+func (p *Player) CardCount() int {
+	return len(p.Deck) + len(p.Hand)
+}
+
+// Deal draws cards from the top of the player's deck into their hand, until the
+// hand holds size cards or the deck runs out.
+func (p *Player) Deal(size int) {
+	for len(p.Hand) < size && len(p.Deck) > 0 {
+		p.Hand = append(p.Hand, p.draw())
+	}
+}
+
+// Choose secretly commits the player to playing the card from their hand.
+func (p *Player) Choose(c Card, nonce string) error {
+	if p.choice != nil {
+		return ErrAlreadyChosen
+	}
+	if !slices.Contains(p.Hand, c) {
+		return fmt.Errorf("%w: %s", ErrNotInHand, c.Slug())
+	}
+	p.choice = &Choice{Card: c, Nonce: nonce}
+	p.Commitment = Commit(c, nonce)
+	return nil
+}
+
+// Chosen reports whether the player has committed to a card this round.
+func (p *Player) Chosen() bool {
+	return p.choice != nil
+}
+
+// ChosenCard returns the card the player committed to, or nil. It must only be
+// shown to the player who made the choice.
+func (p *Player) ChosenCard() *Card {
+	if p.choice == nil {
+		return nil
+	}
+	return &p.choice.Card
+}
+
+// PendingChoice returns the card and nonce the player committed to, or nil. Like
+// ChosenCard, it must only be shown to the player who made the choice.
+func (p *Player) PendingChoice() *Choice {
+	return p.choice
+}
+
+// RestoreChoice sets the choice behind the player's Commitment, as it was stored
+// before the round was played.
+func (p *Player) RestoreChoice(c Choice) {
+	p.choice = &c
+}
+
+// reveal removes the chosen card from the player's hand, after checking it
+// against the Commitment.
+func (p *Player) reveal() (Choice, error) {
+	if p.choice == nil {
+		return Choice{}, fmt.Errorf("%w: %s", ErrNoChoice, p.Role)
+	}
+	choice := *p.choice
+	if !p.Commitment.Verify(choice.Card, choice.Nonce) {
+		return Choice{}, fmt.Errorf("%w: %s", ErrCommitmentMismatch, p.Role)
+	}
+	i := slices.Index(p.Hand, choice.Card)
+	if i < 0 {
+		return Choice{}, fmt.Errorf("%w: %s", ErrNotInHand, choice.Card.Slug())
+	}
+	p.Hand = slices.Delete(p.Hand, i, i+1)
+	p.choice = nil
+	p.Commitment = ""
+	return choice, nil
+}
+
+// PlayHandRound reveals the card chosen by every player holding cards, and
+// resolves them as a battle with the ranker. Ties go to war with cards from the
+// top of the tied players' decks, as in PlayRound. Afterwards, every hand is
+// refilled from its deck up to handSize.
+//
+// It fails without changing any player when a player holding cards has not
+// chosen yet, or when a revealed card does not match its Commitment.
+func PlayHandRound(players []*Player, ranker Ranker, handSize int) (*Battle, error) {
+	contenders := make([]*Player, 0, len(players))
+	for _, p := range players {
+		if len(p.Hand) == 0 {
+			continue
+		}
+		if p.choice == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNoChoice, p.Role)
+		}
+		if !p.Commitment.Verify(p.choice.Card, p.choice.Nonce) {
+			return nil, fmt.Errorf("%w: %s", ErrCommitmentMismatch, p.Role)
+		}
+		contenders = append(contenders, p)
+	}
+
+	r := newRound(ranker)
+	r.battle.Reveals = make(map[string]Choice, len(contenders))
+	for _, p := range contenders {
+		commitment := p.Commitment
+		choice, err := p.reveal()
+		if err != nil {
+			return nil, err
+		}
+		r.play(p, choice.Card)
+		r.battle.Battle[p.Role.String()] = choice.Card
+		r.battle.Reveals[p.Role.String()] = choice
+		r.battle.logf("%s reveals %s for commitment %s", p.Role, choice.Card.Name(), commitment.Short())
+	}
+
+	var battle *Battle
+	if len(contenders) < 2 {
+		for p, cards := range r.played {
+			p.Deck = append(p.Deck, cards...)
+		}
+		battle = r.battle
+	} else {
+		battle = r.resolve(contenders)
+	}
+
+	for _, p := range players {
+		p.Deal(handSize)
+	}
+	return battle, nil
+}
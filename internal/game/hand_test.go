@@ -0,0 +1,90 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitment(t *testing.T) {
+	c := Commit(Card{"S", Ace}, "nonce")
+	assert.Len(t, string(c), 64)
+	assert.True(t, c.Verify(Card{"S", Ace}, "nonce"))
+	assert.False(t, c.Verify(Card{"S", King}, "nonce"))
+	assert.False(t, c.Verify(Card{"S", Ace}, "other"))
+	assert.Equal(t, string(c)[:8], c.Short())
+}
+
+func TestDeal(t *testing.T) {
+	p := &Player{Deck: Deck{{"C", 2}, {"C", 3}, {"C", 4}}}
+	p.Deal(2)
+	assert.Equal(t, Deck{{"C", 2}, {"C", 3}}, p.Hand)
+	assert.Equal(t, Deck{{"C", 4}}, p.Deck)
+	p.Deal(5)
+	assert.Equal(t, Deck{{"C", 2}, {"C", 3}, {"C", 4}}, p.Hand)
+	assert.Empty(t, p.Deck)
+	assert.Equal(t, 3, p.CardCount())
+}
+
+func TestChoose(t *testing.T) {
+	p := &Player{Hand: Deck{{"C", 2}, {"C", 3}}}
+	assert.ErrorIs(t, p.Choose(Card{"C", 4}, "n"), ErrNotInHand)
+	assert.False(t, p.Chosen())
+	assert.Nil(t, p.ChosenCard())
+
+	require.NoError(t, p.Choose(Card{"C", 3}, "n"))
+	assert.True(t, p.Chosen())
+	assert.Equal(t, &Card{"C", 3}, p.ChosenCard())
+	assert.Equal(t, Commit(Card{"C", 3}, "n"), p.Commitment)
+	assert.ErrorIs(t, p.Choose(Card{"C", 2}, "n"), ErrAlreadyChosen)
+}
+
+func TestPlayHandRound(t *testing.T) {
+	host := &Player{Role: Host, Deck: Deck{{"C", 9}}, Hand: Deck{{"C", 2}, {"C", King}}}
+	guest := &Player{Role: Guest, Deck: Deck{{"H", 9}}, Hand: Deck{{"H", 5}, {"H", Queen}}}
+
+	require.NoError(t, host.Choose(Card{"C", King}, "a"))
+	_, err := PlayHandRound([]*Player{host, guest}, HighCardRanker{}, 2)
+	assert.ErrorIs(t, err, ErrNoChoice)
+	assert.Equal(t, Deck{{"C", 2}, {"C", King}}, host.Hand)
+
+	require.NoError(t, guest.Choose(Card{"H", Queen}, "b"))
+	battle, err := PlayHandRound([]*Player{host, guest}, HighCardRanker{}, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, Host, battle.Winner)
+	assert.Equal(t, Choice{Card{"C", King}, "a"}, battle.Reveals["host"])
+	assert.Equal(t, Choice{Card{"H", Queen}, "b"}, battle.Reveals["guest"])
+	assert.Equal(t, Deck{{"C", 2}, {"C", 9}}, host.Hand)
+	assert.Equal(t, Deck{{"C", King}, {"H", Queen}}, host.Deck)
+	assert.Equal(t, Deck{{"H", 5}, {"H", 9}}, guest.Hand)
+	assert.Empty(t, guest.Deck)
+	assert.False(t, host.Chosen())
+	assert.Empty(t, host.Commitment)
+}
+
+func TestPlayHandRoundRejectsTamperedCommitment(t *testing.T) {
+	host := &Player{Role: Host, Hand: Deck{{"C", 2}}}
+	guest := &Player{Role: Guest, Hand: Deck{{"H", 5}}}
+	require.NoError(t, host.Choose(Card{"C", 2}, "a"))
+	require.NoError(t, guest.Choose(Card{"H", 5}, "b"))
+	guest.Commitment = Commit(Card{"H", 5}, "c")
+
+	_, err := PlayHandRound([]*Player{host, guest}, HighCardRanker{}, 1)
+	assert.ErrorIs(t, err, ErrCommitmentMismatch)
+	assert.Equal(t, Deck{{"C", 2}}, host.Hand)
+}
+
+func TestPlayHandRoundWar(t *testing.T) {
+	host := &Player{Role: Host, Deck: Deck{{"C", 3}, {"C", Ace}}, Hand: Deck{{"C", 7}}}
+	guest := &Player{Role: Guest, Deck: Deck{{"H", 3}, {"H", 2}}, Hand: Deck{{"H", 7}}}
+	require.NoError(t, host.Choose(Card{"C", 7}, "a"))
+	require.NoError(t, guest.Choose(Card{"H", 7}, "b"))
+
+	battle, err := PlayHandRound([]*Player{host, guest}, HighCardRanker{}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, Host, battle.Winner)
+	assert.Equal(t, 6, host.CardCount())
+	assert.Equal(t, 0, guest.CardCount())
+}
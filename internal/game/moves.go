@@ -0,0 +1,185 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+var (
+	ErrNotSeated    = errors.New("session is not seated at the game")
+	ErrGameOver     = errors.New("game is over")
+	ErrInvalidMove  = errors.New("move is not allowed by the game rules")
+	ErrGameNotFound = errors.New("game not found")
+	ErrSeatTaken    = errors.New("game has no open seat")
+	ErrConflict     = errors.New("game was changed by another update")
+)
+
+// Controls reports whether the session may move for the player: it owns the
+// seat, or it hosts a hot-seat game and so controls every seat.
+func (g *Game) Controls(sessionID string, p *Player) bool {
+	if sessionID == "" {
+		return false
+	}
+	if p.SessionID == sessionID {
+		return true
+	}
+	return g.HotSeat && g.Player1 != nil && g.Player1.SessionID == sessionID
+}
+
+// SeatFor returns the seat the session moves for. When role is Unknown, it is the
+// first seat the session controls that has not moved this round, so a hot-seat
+// session takes turns for both players.
+func (g *Game) SeatFor(sessionID string, role GameRole) (*Player, error) {
+	controlled := make([]*Player, 0, 2)
+	for _, p := range g.Players() {
+		if g.Controls(sessionID, p) {
+			controlled = append(controlled, p)
+		}
+	}
+	if len(controlled) == 0 {
+		return nil, ErrNotSeated
+	}
+	if role != Unknown {
+		for _, p := range controlled {
+			if p.Role == role {
+				return p, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNotSeated, role)
+	}
+	for _, p := range controlled {
+		if !g.HasMoved(p) {
+			return p, nil
+		}
+	}
+	return controlled[0], nil
+}
+
+// NextToMove returns the role of the first player who has not moved this round,
+// or Host when everyone has.
+func (g *Game) NextToMove() GameRole {
+	for _, p := range g.Players() {
+		if !g.HasMoved(p) {
+			return p.Role
+		}
+	}
+	return Host
+}
+
+// FlipFor marks the player as ready to flip the top card of their deck.
+func (g *Game) FlipFor(p *Player) error {
+	if g.Rules.HandSize > 0 {
+		return fmt.Errorf("%w: choose a card from your hand", ErrInvalidMove)
+	}
+	p.Flipped = true
+	return nil
+}
+
+// ChooseFor secretly commits the player to playing the card from their hand.
+func (g *Game) ChooseFor(p *Player, c Card, nonce string) error {
+	if g.Rules.HandSize == 0 {
+		return fmt.Errorf("%w: flip the top card of your deck", ErrInvalidMove)
+	}
+	if err := p.Choose(c, nonce); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidMove, err)
+	}
+	return nil
+}
+
+// HasMoved reports whether the player has made their move for the current round.
+func (g *Game) HasMoved(p *Player) bool {
+	if g.Rules.HandSize > 0 {
+		return len(p.Hand) == 0 || p.Chosen()
+	}
+	return p.Flipped
+}
+
+// PlayReadyRound plays a round with the game's Rules once every player has
+// moved, records it as the game's Battle, and counts it in Rounds. It does
+// nothing otherwise.
+func (g *Game) PlayReadyRound() error {
+	for _, p := range g.Players() {
+		if !g.HasMoved(p) {
+			return nil
+		}
+	}
+	if g.Rules.HandSize > 0 {
+		battle, err := PlayHandRound(g.Players(), g.Rules.Ranker(), g.Rules.HandSize)
+		if err != nil {
+			return err
+		}
+		g.Battle = battle
+		g.Rounds++
+		return nil
+	}
+	g.Battle = PlayRound(g.Players(), g.Rules.Ranker())
+	g.Rounds++
+	for _, p := range g.Players() {
+		p.Flipped = false
+	}
+	return nil
+}
+
+// Play makes the move for the seat, lets every Autoplayer move, and plays the
+// round once every player has moved. Afterwards, the game's Battle is the round
+// played by the move, if any.
+func (g *Game) Play(seat *Player, move func(*Game, *Player) error) error {
+	if g.Over() {
+		return ErrGameOver
+	}
+	g.Battle = nil
+	if err := move(g, seat); err != nil {
+		return err
+	}
+	if err := g.Autoplay(); err != nil {
+		return err
+	}
+	return g.PlayReadyRound()
+}
+
+// Autoplayer makes the moves for a seat owned by the server instead of a session.
+type Autoplayer interface {
+	Autoplay(g *Game, p *Player) error
+}
+
+var autoplayers = make(map[string]Autoplayer)
+
+// RegisterAutoplayer makes the Autoplayer available to occupy seats under the name.
+func RegisterAutoplayer(name string, a Autoplayer) {
+	autoplayers[name] = a
+}
+
+// Autoplayers returns the names of every registered Autoplayer, sorted.
+func Autoplayers() []string {
+	names := make([]string, 0, len(autoplayers))
+	for name := range autoplayers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsAutoplayer reports whether an Autoplayer is registered under the name.
+func IsAutoplayer(name string) bool {
+	return slices.Contains(Autoplayers(), name)
+}
+
+// Autoplay makes the moves for every seat owned by an Autoplayer that has not
+// moved yet this round.
+func (g *Game) Autoplay() error {
+	for _, p := range g.Players() {
+		if p.Bot == "" || g.HasMoved(p) {
+			continue
+		}
+		a, ok := autoplayers[p.Bot]
+		if !ok {
+			return fmt.Errorf("no autoplayer registered for bot '%s'", p.Bot)
+		}
+		if err := a.Autoplay(g, p); err != nil {
+			return fmt.Errorf("bot '%s' failed to move: %w", p.Bot, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type flipper struct{}
+
+func (flipper) Autoplay(g *Game, p *Player) error {
+	return g.FlipFor(p)
+}
+
+func TestMoveModes(t *testing.T) {
+	classic := &Game{Player1: &Player{Role: Host}}
+	hand := &Game{Rules: Rules{HandSize: 1}, Player1: &Player{Role: Host, Hand: Deck{{"C", 2}}}}
+
+	assert.NoError(t, classic.FlipFor(classic.Player1))
+	assert.ErrorIs(t, classic.ChooseFor(classic.Player1, Card{"C", 2}, "n"), ErrInvalidMove)
+	assert.ErrorIs(t, hand.FlipFor(hand.Player1), ErrInvalidMove)
+	assert.ErrorIs(t, hand.ChooseFor(hand.Player1, Card{"C", 3}, "n"), ErrInvalidMove)
+	assert.NoError(t, hand.ChooseFor(hand.Player1, Card{"C", 2}, "n"))
+}
+
+func TestPlayReadyRound(t *testing.T) {
+	RegisterAutoplayer("test-flipper", flipper{})
+	assert.True(t, IsAutoplayer("test-flipper"))
+
+	g := &Game{
+		Player1: &Player{Role: Host, SessionID: "s", Deck: Deck{{"C", King}}},
+		Player2: &Player{Role: Guest, Bot: "test-flipper", Deck: Deck{{"H", 2}}},
+	}
+	require.NoError(t, g.PlayReadyRound())
+	assert.Nil(t, g.Battle)
+
+	require.NoError(t, g.FlipFor(g.Player1))
+	require.NoError(t, g.PlayReadyRound())
+	assert.Nil(t, g.Battle)
+
+	require.NoError(t, g.Autoplay())
+	require.NoError(t, g.PlayReadyRound())
+	require.NotNil(t, g.Battle)
+	assert.Equal(t, Host, g.Battle.Winner)
+	assert.False(t, g.Player1.Flipped)
+	assert.False(t, g.Player2.Flipped)
+	assert.Equal(t, g.Player1, g.Winner())
+}
+
+func TestSeatFor(t *testing.T) {
+	newGame := func(hotSeat bool) *Game {
+		return &Game{
+			HotSeat: hotSeat,
+			Player1: &Player{Role: Host, SessionID: "host"},
+			Player2: &Player{Role: Guest, SessionID: "guest"},
+		}
+	}
+	hotSeat := &Game{
+		HotSeat: true,
+		Player1: &Player{Role: Host, SessionID: "host", Flipped: true},
+		Player2: &Player{Role: Guest},
+	}
+
+	tests := []struct {
+		name      string
+		game      *Game
+		sessionID string
+		role      GameRole
+		want      GameRole
+		err       error
+	}{
+		{"host owns host seat", newGame(false), "host", Unknown, Host, nil},
+		{"guest owns guest seat", newGame(false), "guest", Guest, Guest, nil},
+		{"host cannot move for guest", newGame(false), "host", Guest, Unknown, ErrNotSeated},
+		{"stranger", newGame(false), "other", Unknown, Unknown, ErrNotSeated},
+		{"empty session", newGame(false), "", Unknown, Unknown, ErrNotSeated},
+		{"hot seat picks role", hotSeat, "host", Host, Host, nil},
+		{"hot seat picks next to move", hotSeat, "host", Unknown, Guest, nil},
+		{"hot seat stranger", hotSeat, "other", Guest, Unknown, ErrNotSeated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seat, err := tt.game.SeatFor(tt.sessionID, tt.role)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, seat.Role)
+		})
+	}
+}
+
+func TestNewGame(t *testing.T) {
+	g := NewGame(Rules{HandSize: 3}, Seating{HotSeat: true}, "s", NewRiffleShuffler())
+
+	assert.Equal(t, "s", g.Player1.SessionID)
+	assert.Equal(t, Host, g.Player1.Role)
+	assert.Equal(t, Guest, g.Player2.Role)
+	assert.True(t, g.HotSeat)
+	for _, p := range g.Players() {
+		assert.Len(t, p.Hand, 3)
+		assert.Len(t, p.Deck, 23)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	RegisterAutoplayer("test-flipper", flipper{})
+	g := &Game{
+		Player1: &Player{Role: Host, SessionID: "s", Deck: Deck{{"C", King}}},
+		Player2: &Player{Role: Guest, Bot: "test-flipper", Deck: Deck{{"H", 2}}},
+	}
+	flip := func(g *Game, p *Player) error { return g.FlipFor(p) }
+
+	require.NoError(t, g.Play(g.Player1, flip))
+	require.NotNil(t, g.Battle)
+	assert.Equal(t, Host, g.Battle.Winner)
+	assert.ErrorIs(t, g.Play(g.Player1, flip), ErrGameOver)
+}
+
+func TestPlayDrawsAfterMaxRounds(t *testing.T) {
+	RegisterAutoplayer("test-flipper", flipper{})
+	g := &Game{
+		Player1: &Player{Role: Host, SessionID: "s", Deck: Deck{{"C", King}, {"C", 2}}},
+		Player2: &Player{Role: Guest, Bot: "test-flipper", Deck: Deck{{"H", 2}, {"H", King}}},
+		Rounds:  MaxRounds - 1,
+	}
+	flip := func(g *Game, p *Player) error { return g.FlipFor(p) }
+
+	require.NoError(t, g.Play(g.Player1, flip))
+	assert.Equal(t, MaxRounds, g.Rounds)
+	assert.Nil(t, g.Winner())
+	assert.True(t, g.Drawn())
+	assert.True(t, g.Over())
+	assert.ErrorIs(t, g.Play(g.Player1, flip), ErrGameOver)
+}
+
+func TestParseGameID(t *testing.T) {
+	id, err := ParseGameID("12")
+	require.NoError(t, err)
+	assert.Equal(t, 12, id)
+	_, err = ParseGameID("twelve")
+	assert.ErrorIs(t, err, ErrGameNotFound)
+}
@@ -0,0 +1,222 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PackedCard is a card of the StandardDeck packed in one byte, with the index of
+// its suit in the high bits and its face value in the low four. The zero
+// PackedCard is no card.
+type PackedCard uint8
+
+// packedSuits lists the suits by their index in a PackedCard.
+var packedSuits = [4]Suit{SuitClub, SuitDiamond, SuitHeart, SuitSpade}
+
+// PackCard returns the card packed in one byte, or a *SlugError when it is not
+// in the StandardDeck.
+func PackCard(c Card) (PackedCard, error) {
+	suit := -1
+	for i, s := range packedSuits {
+		if s == c.Suit {
+			suit = i
+		}
+	}
+	if suit < 0 {
+		return 0, &SlugError{Slug: c.Slug(), Err: ErrUnknownSuit}
+	}
+	if c.Value < 2 || c.Value > Ace {
+		return 0, &SlugError{Slug: c.Slug(), Err: ErrUnknownValue}
+	}
+	return PackedCard(suit<<4 | int(c.Value)), nil
+}
+
+// Valid reports whether p is a packed card of the StandardDeck.
+func (p PackedCard) Valid() bool {
+	v := FaceValue(p & 0xf)
+	return p>>6 == 0 && v >= 2 && v <= Ace
+}
+
+// Card returns the card, or the zero Card when p is no card.
+func (p PackedCard) Card() Card {
+	if p == 0 {
+		return Card{}
+	}
+	return Card{Suit: packedSuits[p>>4&3], Value: FaceValue(p & 0xf)}
+}
+
+// deckEncodingV1 is the first byte of decks encoded by Deck.MarshalBinary, which
+// is followed by one PackedCard per card. Another encoding would start with
+// another version.
+const deckEncodingV1 = 1
+
+var (
+	ErrUnknownDeckEncoding = errors.New("unknown deck encoding")
+	ErrInvalidPackedCard   = errors.New("byte is not a packed card")
+)
+
+// MarshalBinary encodes the deck as a version byte followed by one PackedCard per
+// card, or returns a *SlugError for the first card not in the StandardDeck.
+func (d Deck) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 1, len(d)+1)
+	data[0] = deckEncodingV1
+	for i, c := range d {
+		p, err := PackCard(c)
+		if err != nil {
+			var slugErr *SlugError
+			if errors.As(err, &slugErr) {
+				slugErr.Pos = i
+			}
+			return nil, err
+		}
+		data = append(data, byte(p))
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a deck encoded by MarshalBinary.
+func (d *Deck) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%w: no version byte", ErrUnknownDeckEncoding)
+	}
+	if data[0] != deckEncodingV1 {
+		return fmt.Errorf("%w: version %d", ErrUnknownDeckEncoding, data[0])
+	}
+	deck := make(Deck, len(data)-1)
+	for i, b := range data[1:] {
+		p := PackedCard(b)
+		if !p.Valid() {
+			return fmt.Errorf("%w: 0x%02x at position %d", ErrInvalidPackedCard, b, i)
+		}
+		deck[i] = p.Card()
+	}
+	*d = deck
+	return nil
+}
+
+// DeckCapacity is the number of cards a PackedDeck holds when full, the size of
+// the StandardDeck.
+const DeckCapacity = 52
+
+var ErrDeckFull = fmt.Errorf("deck already holds %d cards", DeckCapacity)
+
+// PackedDeck is a deck of up to DeckCapacity packed cards. Like a player's Deck,
+// cards are drawn from the top and added at the bottom, and neither allocates.
+// The zero PackedDeck is empty.
+type PackedDeck struct {
+	cards [DeckCapacity]PackedCard
+	// top is the index of the top card in cards, which wraps around.
+	top int
+	n   int
+}
+
+// PackDeck returns the deck packed, or an error when it holds a card that is not
+// in the StandardDeck or more than DeckCapacity cards.
+func PackDeck(d Deck) (PackedDeck, error) {
+	var packed PackedDeck
+	if len(d) > DeckCapacity {
+		return packed, fmt.Errorf("%w: got %d", ErrDeckFull, len(d))
+	}
+	for i, c := range d {
+		p, err := PackCard(c)
+		if err != nil {
+			var slugErr *SlugError
+			if errors.As(err, &slugErr) {
+				slugErr.Pos = i
+			}
+			return PackedDeck{}, err
+		}
+		packed.Push(p)
+	}
+	return packed, nil
+}
+
+// Deck returns the cards from the top of the deck.
+func (d *PackedDeck) Deck() Deck {
+	deck := make(Deck, d.n)
+	for i := range deck {
+		deck[i] = d.At(i).Card()
+	}
+	return deck
+}
+
+// Len returns the number of cards in the deck.
+func (d *PackedDeck) Len() int {
+	return d.n
+}
+
+// At returns the i-th card from the top of the deck.
+func (d *PackedDeck) At(i int) PackedCard {
+	if i < 0 || i >= d.n {
+		panic(fmt.Sprintf("card %d of a deck of %d", i, d.n))
+	}
+	return d.cards[(d.top+i)%DeckCapacity]
+}
+
+// Push adds the card at the bottom of the deck, and reports whether it fit.
+func (d *PackedDeck) Push(c PackedCard) bool {
+	if d.n == DeckCapacity {
+		return false
+	}
+	i := d.top + d.n
+	if i >= DeckCapacity {
+		i -= DeckCapacity
+	}
+	d.cards[i] = c
+	d.n++
+	return true
+}
+
+// Pop removes and returns the top card of the deck, and reports whether the deck
+// held one.
+func (d *PackedDeck) Pop() (PackedCard, bool) {
+	if d.n == 0 {
+		return 0, false
+	}
+	c := d.cards[d.top]
+	d.top++
+	if d.top == DeckCapacity {
+		d.top = 0
+	}
+	d.n--
+	return c, true
+}
+
+// Riffle shuffles the deck once, in the same order as RiffleShuffler.Shuffle
+// given the same random numbers.
+func (d *PackedDeck) Riffle(random func() float32) {
+	var left, right [DeckCapacity]PackedCard
+	nl, nr := 0, 0
+	for i, j := 0, d.top; i < d.n; i, j = i+1, j+1 {
+		if j == DeckCapacity {
+			j = 0
+		}
+		if i&1 == 1 {
+			left[nl] = d.cards[j]
+			nl++
+		} else {
+			right[nr] = d.cards[j]
+			nr++
+		}
+	}
+	li, ri := 0, 0
+	for i := 0; i < d.n; i++ {
+		leftPreferred := random() < 0.5
+		if li < nl && (ri == nr || leftPreferred) {
+			d.cards[i] = left[li]
+			li++
+		} else {
+			d.cards[i] = right[ri]
+			ri++
+		}
+	}
+	d.top = 0
+}
+
+// Shuffle riffles the deck as many times as Deck.Shuffle, in the same order as
+// Deck.Shuffle with a RiffleShuffler given the same random numbers.
+func (d *PackedDeck) Shuffle(random func() float32) {
+	for i := 0; i < defaultShuffleRounds; i++ {
+		d.Riffle(random)
+	}
+}
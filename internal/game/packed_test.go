@@ -0,0 +1,147 @@
+package game
+
+import (
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackCard(t *testing.T) {
+	seen := make(map[PackedCard]bool)
+	for _, c := range StandardDeck.Cards() {
+		p, err := PackCard(c)
+		require.NoError(t, err)
+		assert.NotZero(t, p)
+		assert.False(t, seen[p], "%s packed like another card", c.Slug())
+		seen[p] = true
+		assert.Equal(t, c, p.Card())
+	}
+	assert.Equal(t, Card{}, PackedCard(0).Card())
+
+	_, err := PackCard(Card{"X", 2})
+	assert.ErrorIs(t, err, ErrUnknownSuit)
+	_, err = PackCard(Card{SuitClub, 15})
+	assert.ErrorIs(t, err, ErrUnknownValue)
+}
+
+func TestPackedDeck(t *testing.T) {
+	cards := Deck{{SuitClub, 2}, {SuitHeart, Ace}}
+	d, err := PackDeck(cards)
+	require.NoError(t, err)
+	assert.Equal(t, 2, d.Len())
+
+	// Cycle every card through the deck, wrapping it around more than once.
+	for i := 0; i < 3*DeckCapacity; i++ {
+		c, ok := d.Pop()
+		require.True(t, ok)
+		require.True(t, d.Push(c))
+	}
+	assert.Equal(t, cards, d.Deck())
+
+	for _, c := range cards {
+		p, ok := d.Pop()
+		require.True(t, ok)
+		assert.Equal(t, c, p.Card())
+	}
+	_, ok := d.Pop()
+	assert.False(t, ok)
+
+	full, err := PackDeck(StandardDeck.Cards())
+	require.NoError(t, err)
+	assert.False(t, full.Push(full.At(0)))
+	assert.Equal(t, StandardDeck.Cards(), full.Deck())
+
+	_, err = PackDeck(append(StandardDeck.Cards(), Card{SuitClub, 2}))
+	assert.ErrorIs(t, err, ErrDeckFull)
+	_, err = PackDeck(Deck{{SuitClub, 2}, {"X", 2}})
+	var slugErr *SlugError
+	require.ErrorAs(t, err, &slugErr)
+	assert.Equal(t, 1, slugErr.Pos)
+}
+
+func TestPackedShuffleMatchesRiffleShuffler(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 7, 26, 51, 52} {
+		for seed := uint64(0); seed < 10; seed++ {
+			deck := slices.Clone(StandardDeck.Cards()[:size])
+			packed, err := PackDeck(deck)
+			require.NoError(t, err)
+
+			deck.Shuffle(seededShuffler(seed))
+			packed.Shuffle(rand.New(rand.NewPCG(seed, seed)).Float32)
+			assert.Equal(t, deck, packed.Deck(), "%d cards with seed %d", size, seed)
+		}
+	}
+}
+
+func TestPackedDeckDoesNotAllocate(t *testing.T) {
+	d, err := PackDeck(StandardDeck.Cards())
+	require.NoError(t, err)
+	random := rand.New(rand.NewPCG(1, 1)).Float32
+	allocs := testing.AllocsPerRun(100, func() {
+		c, _ := d.Pop()
+		d.Push(c)
+		d.Shuffle(random)
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkDeckShuffle(b *testing.B) {
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+	s := NewRiffleShuffler()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d := NewDeck()
+		d.Shuffle(s)
+	}
+}
+
+func BenchmarkPackedDeckShuffle(b *testing.B) {
+	d, err := PackDeck(StandardDeck.Cards())
+	require.NoError(b, err)
+	random := rand.New(rand.NewPCG(1, 1)).Float32
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.Shuffle(random)
+	}
+}
+
+func TestDeckBinary(t *testing.T) {
+	testCases := []struct {
+		scenario string
+		deck     Deck
+		data     []byte
+	}{
+		{scenario: "empty", deck: Deck{}, data: []byte{1}},
+		{scenario: "cards", deck: Deck{{SuitClub, 2}, {SuitHeart, 10}, {SuitSpade, Ace}}, data: []byte{1, 0x02, 0x2a, 0x3e}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			data, err := tc.deck.MarshalBinary()
+			require.NoError(t, err)
+			assert.Equal(t, tc.data, data)
+			var d Deck
+			require.NoError(t, d.UnmarshalBinary(data))
+			assert.Equal(t, tc.deck, d)
+		})
+	}
+
+	_, err := Deck{{SuitClub, 2}, {"X", 2}}.MarshalBinary()
+	var slugErr *SlugError
+	require.ErrorAs(t, err, &slugErr)
+	assert.Equal(t, 1, slugErr.Pos)
+
+	var d Deck
+	assert.ErrorIs(t, d.UnmarshalBinary(nil), ErrUnknownDeckEncoding)
+	assert.ErrorIs(t, d.UnmarshalBinary([]byte{2, 0x02}), ErrUnknownDeckEncoding)
+	assert.ErrorIs(t, d.UnmarshalBinary([]byte{1, 0x02, 0x40}), ErrInvalidPackedCard)
+	assert.ErrorIs(t, d.UnmarshalBinary([]byte{1, 0x01}), ErrInvalidPackedCard)
+	assert.Nil(t, d)
+}
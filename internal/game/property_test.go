@@ -0,0 +1,101 @@
+package game
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sorted returns a copy of the deck ordered by suit and value, to compare decks
+// holding the same cards in any order.
+func sorted(d Deck) Deck {
+	c := slices.Clone(d)
+	slices.SortFunc(c, func(a, b Card) int {
+		if a.Suit != b.Suit {
+			if a.Suit < b.Suit {
+				return -1
+			}
+			return 1
+		}
+		return int(a.Value - b.Value)
+	})
+	return c
+}
+
+// seededShuffler returns a RiffleShuffler whose random numbers come from seed.
+func seededShuffler(seed uint64) RiffleShuffler {
+	return RiffleShuffler{random: rand.New(rand.NewPCG(seed, seed)).Float32}
+}
+
+func TestShufflePermutes(t *testing.T) {
+	err := quick.Check(func(seed uint64, size uint8) bool {
+		d := StandardDeck.Cards()[:int(size)%(StandardDeck.Size()+1)]
+		shuffled := seededShuffler(seed).Shuffle(slices.Clone(d))
+		repeated := slices.Clone(d)
+		repeated.Shuffle(seededShuffler(seed))
+		return slices.Equal(sorted(d), sorted(shuffled)) && slices.Equal(sorted(d), sorted(repeated))
+	}, nil)
+	assert.NoError(t, err)
+}
+
+func TestCutPreservesCards(t *testing.T) {
+	err := quick.Check(func(size uint8) bool {
+		d := StandardDeck.Cards()[:int(size)%(StandardDeck.Size()+1)]
+		left, right := d.Cut()
+		whole := append(slices.Clone(left), right...)
+		return slices.Equal(sorted(d), sorted(whole)) && len(right)-len(left) == len(d)%2
+	}, nil)
+	assert.NoError(t, err)
+}
+
+func TestRoundsConserveCards(t *testing.T) {
+	variants := []Variant{VariantClassic, VariantPeace, VariantTwoBeatsAce}
+	err := quick.Check(func(seed uint64, variant, handSize uint8, tieBreak bool) bool {
+		rules := Rules{
+			Variant:  variants[int(variant)%len(variants)],
+			HandSize: int(handSize) % (MaxHandSize + 1),
+		}
+		if tieBreak {
+			rules.SuitOrder = DefaultSuitOrder
+		}
+		g := NewGame(rules, Seating{HotSeat: true}, "host", seededShuffler(seed))
+		random := rand.New(rand.NewPCG(seed, seed))
+		rounds := 0
+		for !g.Over() {
+			if rounds >= MaxRounds {
+				t.Logf("game with %+v still playing after %d rounds", rules, rounds)
+				return false
+			}
+			seat, err := g.SeatFor("host", Unknown)
+			require.NoError(t, err)
+			require.NoError(t, g.Play(seat, func(g *Game, p *Player) error {
+				if rules.HandSize == 0 {
+					return g.FlipFor(p)
+				}
+				return g.ChooseFor(p, p.Hand[random.IntN(len(p.Hand))], "nonce")
+			}))
+			if g.Battle != nil {
+				rounds++
+			}
+			if err := g.CheckCards(); err != nil {
+				t.Logf("round %d with %+v: %v", rounds, rules, err)
+				return false
+			}
+		}
+		assert.Equal(t, rounds, g.Rounds)
+		seat, err := g.SeatFor("host", Unknown)
+		require.NoError(t, err)
+		assert.ErrorIs(t, g.Play(seat, (*Game).FlipFor), ErrGameOver)
+		if g.Drawn() {
+			assert.Equal(t, MaxRounds, g.Rounds)
+		} else {
+			assert.Equal(t, StandardDeck.Size(), g.Winner().CardCount())
+		}
+		return true
+	}, &quick.Config{MaxCount: 50})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,236 @@
+package game
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Ranker orders cards during a battle. Compare returns a positive number when a
+// beats b, a negative number when b beats a, and zero when they tie.
+type Ranker interface {
+	Compare(a, b Card) int
+}
+
+// Describer is implemented by Rankers that can explain, for the round log, why
+// card a beats card b.
+type Describer interface {
+	Describe(a, b Card) string
+}
+
+// describe explains why a beats b under the ranker.
+func describe(r Ranker, a, b Card) string {
+	if d, ok := r.(Describer); ok {
+		return d.Describe(a, b)
+	}
+	return fmt.Sprintf("%s beats %s", a.Name(), b.Name())
+}
+
+// HighCardRanker is the classic ranking, where the higher face value wins.
+type HighCardRanker struct{}
+
+func (HighCardRanker) Compare(a, b Card) int {
+	return cmp.Compare(a.Value, b.Value)
+}
+
+// LowCardRanker is the "Peace" ranking, where the lower face value wins.
+type LowCardRanker struct{}
+
+func (LowCardRanker) Compare(a, b Card) int {
+	return cmp.Compare(b.Value, a.Value)
+}
+
+// TwoBeatsAceRanker wraps another ranking with the exception that a Two beats an
+// Ace. All other comparisons are left to the wrapped Ranker.
+type TwoBeatsAceRanker struct {
+	Ranker Ranker
+}
+
+func (r TwoBeatsAceRanker) Compare(a, b Card) int {
+	if a.Value == 2 && b.Value == Ace {
+		return 1
+	}
+	if a.Value == Ace && b.Value == 2 {
+		return -1
+	}
+	return r.Ranker.Compare(a, b)
+}
+
+func (r TwoBeatsAceRanker) Describe(a, b Card) string {
+	if a.Value == 2 && b.Value == Ace {
+		return fmt.Sprintf("%s beats %s, Two beats Ace", a.Name(), b.Name())
+	}
+	return describe(r.Ranker, a, b)
+}
+
+// SuitOrder lists every Suit from the highest priority to the lowest.
+type SuitOrder []Suit
+
+// DefaultSuitOrder ranks Spades > Hearts > Diamonds > Clubs.
+var DefaultSuitOrder = SuitOrder{SuitSpade, SuitHeart, SuitDiamond, SuitClub}
+
+var ErrInvalidSuitOrder = errors.New("suit order must list every suit exactly once")
+
+// ConvertSuitOrder converts a string of suit slugs like "SHDC" into a SuitOrder.
+// An empty string converts to a nil SuitOrder, meaning ties go to war.
+func ConvertSuitOrder(s string) (SuitOrder, error) {
+	if s == "" {
+		return nil, nil
+	}
+	order := make(SuitOrder, 0, len(SuitNames))
+	for _, r := range strings.ToUpper(s) {
+		suit := Suit(r)
+		if _, ok := SuitNames[suit]; !ok || slices.Contains(order, suit) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSuitOrder, s)
+		}
+		order = append(order, suit)
+	}
+	if len(order) != len(SuitNames) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSuitOrder, s)
+	}
+	return order, nil
+}
+
+// Return the serialized SuitOrder
+func (o SuitOrder) String() string {
+	var b strings.Builder
+	for _, s := range o {
+		b.WriteString(string(s))
+	}
+	return b.String()
+}
+
+// Name returns the SuitOrder for display, like "Spades > Hearts > Diamonds > Clubs".
+func (o SuitOrder) Name() string {
+	names := make([]string, len(o))
+	for i, s := range o {
+		names[i] = s.Name()
+	}
+	return strings.Join(names, " > ")
+}
+
+// priority returns the rank of the suit in the order, where higher wins.
+func (o SuitOrder) priority(s Suit) int {
+	i := slices.Index(o, s)
+	if i < 0 {
+		return i
+	}
+	return len(o) - i
+}
+
+// SuitTieBreaker wraps another ranking, and breaks any tie it reports using the
+// SuitOrder instead of going to war.
+type SuitTieBreaker struct {
+	Ranker Ranker
+	Order  SuitOrder
+}
+
+func (r SuitTieBreaker) Compare(a, b Card) int {
+	if c := r.Ranker.Compare(a, b); c != 0 {
+		return c
+	}
+	return cmp.Compare(r.Order.priority(a.Suit), r.Order.priority(b.Suit))
+}
+
+func (r SuitTieBreaker) Describe(a, b Card) string {
+	if r.Ranker.Compare(a, b) != 0 {
+		return describe(r.Ranker, a, b)
+	}
+	return fmt.Sprintf("%s beats %s, %s outrank %s", a.Name(), b.Name(), a.Suit.Name(), b.Suit.Name())
+}
+
+// Variant names the rules used to rank cards in a game.
+type Variant string
+
+const (
+	VariantClassic     Variant = "classic"
+	VariantPeace       Variant = "peace"
+	VariantTwoBeatsAce Variant = "two-beats-ace"
+)
+
+var VariantNames = map[Variant]string{
+	VariantClassic:     "Classic",
+	VariantPeace:       "Peace (low card wins)",
+	VariantTwoBeatsAce: "Two beats Ace",
+}
+
+// Variants lists every supported Variant in display order.
+var Variants = []Variant{VariantClassic, VariantPeace, VariantTwoBeatsAce}
+
+func (v Variant) Name() string {
+	name, ok := VariantNames[v]
+	if !ok {
+		return ""
+	}
+	return name
+}
+
+// ConvertVariant converts a stored variant, falling back to VariantClassic when
+// the value is not recognized. Submitted variants are checked by NewRules.
+func ConvertVariant(s string) Variant {
+	v := Variant(s)
+	if _, ok := VariantNames[v]; !ok {
+		return VariantClassic
+	}
+	return v
+}
+
+// Ranker returns the card ranking used by the variant.
+func (v Variant) Ranker() Ranker {
+	switch v {
+	case VariantPeace:
+		return LowCardRanker{}
+	case VariantTwoBeatsAce:
+		return TwoBeatsAceRanker{Ranker: HighCardRanker{}}
+	}
+	return HighCardRanker{}
+}
+
+// Rules selects how cards are ranked in a game.
+type Rules struct {
+	Variant Variant
+	// SuitOrder breaks ties instead of going to war, when set.
+	SuitOrder SuitOrder
+	// HandSize is the number of cards each player holds to choose from in the
+	// hand War variant. Players flip the top card of their deck when it is zero.
+	HandSize int
+}
+
+var (
+	ErrInvalidVariant  = errors.New("unknown variant")
+	ErrInvalidHandSize = fmt.Errorf("hand size must be between 0 and %d", MaxHandSize)
+)
+
+// NewRules returns the Rules for the named variant, checking the variant, the
+// hand size and the suit order. An empty variant is VariantClassic, and an empty
+// suit order sends ties to war.
+func NewRules(variant string, handSize int, suitOrder string) (Rules, error) {
+	if _, ok := VariantNames[Variant(variant)]; variant != "" && !ok {
+		return Rules{}, fmt.Errorf("%w '%s'", ErrInvalidVariant, variant)
+	}
+	if handSize < 0 || handSize > MaxHandSize {
+		return Rules{}, ErrInvalidHandSize
+	}
+	order, err := ConvertSuitOrder(suitOrder)
+	if err != nil {
+		return Rules{}, err
+	}
+	return Rules{Variant: ConvertVariant(variant), SuitOrder: order, HandSize: handSize}, nil
+}
+
+// Deck returns the deck games with the rules are dealt from. Every variant is
+// played with the StandardDeck.
+func (r Rules) Deck() DeckSpec {
+	return StandardDeck
+}
+
+// Ranker returns the card ranking used by the rules.
+func (r Rules) Ranker() Ranker {
+	ranker := r.Variant.Ranker()
+	if len(r.SuitOrder) > 0 {
+		return SuitTieBreaker{Ranker: ranker, Order: r.SuitOrder}
+	}
+	return ranker
+}
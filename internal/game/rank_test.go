@@ -0,0 +1,129 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankers(t *testing.T) {
+	testCases := []struct {
+		scenario string
+		ranker   Ranker
+		a        Card
+		b        Card
+		expected int
+	}{
+		{"classic higher wins", HighCardRanker{}, Card{"C", King}, Card{"H", 10}, 1},
+		{"classic lower loses", HighCardRanker{}, Card{"C", 2}, Card{"H", Ace}, -1},
+		{"classic tie ignores suit", HighCardRanker{}, Card{"C", 7}, Card{"S", 7}, 0},
+		{"peace lower wins", LowCardRanker{}, Card{"C", 2}, Card{"H", Ace}, 1},
+		{"peace higher loses", LowCardRanker{}, Card{"C", King}, Card{"H", 10}, -1},
+		{"peace tie", LowCardRanker{}, Card{"C", 7}, Card{"S", 7}, 0},
+		{"two beats ace", TwoBeatsAceRanker{HighCardRanker{}}, Card{"C", 2}, Card{"H", Ace}, 1},
+		{"ace loses to two", TwoBeatsAceRanker{HighCardRanker{}}, Card{"C", Ace}, Card{"H", 2}, -1},
+		{"ace beats king", TwoBeatsAceRanker{HighCardRanker{}}, Card{"C", Ace}, Card{"H", King}, 1},
+		{"two loses to three", TwoBeatsAceRanker{HighCardRanker{}}, Card{"C", 2}, Card{"H", 3}, -1},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.scenario, func(t *testing.T) {
+			assert.Equal(t, c.expected, c.ranker.Compare(c.a, c.b))
+		})
+	}
+}
+
+func TestConvertVariant(t *testing.T) {
+	testCases := []struct {
+		raw      string
+		expected Variant
+	}{
+		{"classic", VariantClassic},
+		{"peace", VariantPeace},
+		{"two-beats-ace", VariantTwoBeatsAce},
+		{"", VariantClassic},
+		{"unknown", VariantClassic},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.raw, func(t *testing.T) {
+			assert.Equal(t, c.expected, ConvertVariant(c.raw))
+		})
+	}
+}
+
+func TestNewRules(t *testing.T) {
+	testCases := []struct {
+		scenario  string
+		variant   string
+		handSize  int
+		suitOrder string
+		expected  Rules
+		err       error
+	}{
+		{"default", "", 0, "", Rules{Variant: VariantClassic}, nil},
+		{"variant", "peace", 3, "", Rules{Variant: VariantPeace, HandSize: 3}, nil},
+		{"suit order", "classic", 0, "SHDC", Rules{Variant: VariantClassic, SuitOrder: DefaultSuitOrder}, nil},
+		{"unknown variant", "bogus", 0, "", Rules{}, ErrInvalidVariant},
+		{"hand size", "classic", MaxHandSize + 1, "", Rules{}, ErrInvalidHandSize},
+		{"invalid suit order", "classic", 0, "SSSS", Rules{}, ErrInvalidSuitOrder},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.scenario, func(t *testing.T) {
+			rules, err := NewRules(c.variant, c.handSize, c.suitOrder)
+			assert.ErrorIs(t, err, c.err)
+			assert.Equal(t, c.expected, rules)
+		})
+	}
+}
+
+func TestSuitTieBreaker(t *testing.T) {
+	r := Rules{Variant: VariantClassic, SuitOrder: DefaultSuitOrder}.Ranker()
+	testCases := []struct {
+		scenario string
+		a        Card
+		b        Card
+		expected int
+	}{
+		{"value still wins", Card{"C", King}, Card{"S", 10}, 1},
+		{"spades beat hearts", Card{"S", 7}, Card{"H", 7}, 1},
+		{"clubs lose to diamonds", Card{"C", 7}, Card{"D", 7}, -1},
+		{"same card ties", Card{"C", 7}, Card{"C", 7}, 0},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.scenario, func(t *testing.T) {
+			assert.Equal(t, c.expected, r.Compare(c.a, c.b))
+		})
+	}
+}
+
+func TestConvertSuitOrder(t *testing.T) {
+	testCases := []struct {
+		raw      string
+		expected SuitOrder
+		err      error
+	}{
+		{"", nil, nil},
+		{"SHDC", DefaultSuitOrder, nil},
+		{"cdhs", SuitOrder{SuitClub, SuitDiamond, SuitHeart, SuitSpade}, nil},
+		{"SHD", nil, ErrInvalidSuitOrder},
+		{"SHDD", nil, ErrInvalidSuitOrder},
+		{"SHDX", nil, ErrInvalidSuitOrder},
+		{"SHDCS", nil, ErrInvalidSuitOrder},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.raw, func(t *testing.T) {
+			order, err := ConvertSuitOrder(c.raw)
+			assert.ErrorIs(t, err, c.err)
+			assert.Equal(t, c.expected, order)
+		})
+	}
+}
+
+func TestSuitOrderName(t *testing.T) {
+	assert.Equal(t, "SHDC", DefaultSuitOrder.String())
+	assert.Equal(t, "Spades > Hearts > Diamonds > Clubs", DefaultSuitOrder.Name())
+}
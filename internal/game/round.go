@@ -0,0 +1,159 @@
+package game
+
+import "fmt"
+
+// warSize is the number of cards each tied player places face down before
+// flipping again during a war.
+const warSize = 3
+
+// draw removes and returns the top card of the player's deck.
+func (p *Player) draw() Card {
+	c := p.Deck[0]
+	p.Deck = p.Deck[1:]
+	return c
+}
+
+// leaders returns the players whose face-up card ranks highest.
+func leaders(players []*Player, faceUp map[*Player]Card, ranker Ranker) []*Player {
+	best := make([]*Player, 0, len(players))
+	for _, p := range players {
+		if len(best) == 0 {
+			best = append(best, p)
+			continue
+		}
+		switch c := ranker.Compare(faceUp[p], faceUp[best[0]]); {
+		case c > 0:
+			best = append(best[:0], p)
+		case c == 0:
+			best = append(best, p)
+		}
+	}
+	return best
+}
+
+// round tracks the cards played while a Battle is resolved.
+type round struct {
+	battle *Battle
+	ranker Ranker
+	pot    Deck
+	played map[*Player]Deck
+	faceUp map[*Player]Card
+}
+
+func newRound(ranker Ranker) *round {
+	return &round{
+		battle: &Battle{
+			Battle: make(map[string]Card),
+			War:    make(map[string][]Card),
+			Log:    make([]string, 0),
+		},
+		ranker: ranker,
+		pot:    make(Deck, 0),
+		played: make(map[*Player]Deck),
+		faceUp: make(map[*Player]Card),
+	}
+}
+
+// play adds the player's card to the pot, face up.
+func (r *round) play(p *Player, c Card) {
+	r.faceUp[p] = c
+	r.played[p] = append(r.played[p], c)
+	r.pot = append(r.pot, c)
+}
+
+// award moves every card in the pot to the bottom of the winner's deck.
+func (r *round) award(winner *Player) *Battle {
+	winner.Deck = append(winner.Deck, r.pot...)
+	r.battle.Winner = winner.Role
+	r.battle.logf("%s wins %d cards", winner.Role, len(r.pot))
+	return r.battle
+}
+
+// resolve compares the face-up cards of the contenders, and goes to war until
+// one of them wins or none of them can continue.
+func (r *round) resolve(contenders []*Player) *Battle {
+	for {
+		tied := leaders(contenders, r.faceUp, r.ranker)
+		if len(tied) == 1 {
+			winner := tied[0]
+			others := make([]*Player, 0, len(contenders)-1)
+			for _, p := range contenders {
+				if p != winner {
+					others = append(others, p)
+				}
+			}
+			runnerUp := leaders(others, r.faceUp, r.ranker)[0]
+			r.battle.logf("%s", describe(r.ranker, r.faceUp[winner], r.faceUp[runnerUp]))
+			return r.award(winner)
+		}
+
+		contenders = make([]*Player, 0, len(tied))
+		for _, p := range tied {
+			if len(p.Deck) > 0 {
+				contenders = append(contenders, p)
+			} else {
+				r.battle.logf("%s has no cards left for war", p.Role)
+			}
+		}
+		if len(contenders) == 1 {
+			return r.award(contenders[0])
+		}
+		if len(contenders) == 0 {
+			for p, cards := range r.played {
+				p.Deck = append(p.Deck, cards...)
+			}
+			r.battle.logf("Nobody can continue the war, played cards are returned")
+			return r.battle
+		}
+
+		r.battle.logf("War!")
+		for _, p := range contenders {
+			n := min(warSize, len(p.Deck)-1)
+			for i := 0; i <= n; i++ {
+				c := p.draw()
+				r.play(p, c)
+				r.battle.War[p.Role.String()] = append(r.battle.War[p.Role.String()], c)
+			}
+			cards := "cards"
+			if n == 1 {
+				cards = "card"
+			}
+			r.battle.logf("%s places %d %s face down and flips %s", p.Role, n, cards, r.faceUp[p].Name())
+		}
+	}
+}
+
+// PlayRound flips the top card of every player's deck and moves all played cards
+// to the bottom of the deck belonging to the player whose card ranks highest.
+// Players tied for the highest card go to war: each places up to warSize cards
+// face down and flips again, until one player wins or every tied player has run
+// out of cards. When nobody can win, each player takes back the cards they played.
+//
+// Players with empty decks sit the round out. The returned Battle records the
+// cards played and a log of each step, and its Winner is Unknown when the round
+// had no winner.
+func PlayRound(players []*Player, ranker Ranker) *Battle {
+	r := newRound(ranker)
+
+	contenders := make([]*Player, 0, len(players))
+	for _, p := range players {
+		if len(p.Deck) > 0 {
+			contenders = append(contenders, p)
+		}
+	}
+	if len(contenders) < 2 {
+		return r.battle
+	}
+
+	for _, p := range contenders {
+		c := p.draw()
+		r.play(p, c)
+		r.battle.Battle[p.Role.String()] = c
+		r.battle.logf("%s flips %s", p.Role, c.Name())
+	}
+	return r.resolve(contenders)
+}
+
+func (b *Battle) logf(format string, args ...any) {
+	b.Log = append(b.Log, fmt.Sprintf(format, args...))
+}
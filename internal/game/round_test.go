@@ -0,0 +1,187 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlayRound(t *testing.T) {
+	testCases := []struct {
+		scenario      string
+		ranker        Ranker
+		host          Deck
+		guest         Deck
+		expectedHost  Deck
+		expectedGuest Deck
+		winner        GameRole
+		war           map[string][]Card
+	}{
+		{
+			scenario:      "host wins battle",
+			ranker:        HighCardRanker{},
+			host:          Deck{{"C", King}, {"C", 2}},
+			guest:         Deck{{"H", 10}},
+			expectedHost:  Deck{{"C", 2}, {"C", King}, {"H", 10}},
+			expectedGuest: Deck{},
+			winner:        Host,
+			war:           map[string][]Card{},
+		},
+		{
+			scenario:      "peace guest wins battle",
+			ranker:        LowCardRanker{},
+			host:          Deck{{"C", King}},
+			guest:         Deck{{"H", 10}, {"H", 3}},
+			expectedHost:  Deck{},
+			expectedGuest: Deck{{"H", 3}, {"C", King}, {"H", 10}},
+			winner:        Guest,
+			war:           map[string][]Card{},
+		},
+		{
+			scenario:      "war",
+			ranker:        HighCardRanker{},
+			host:          Deck{{"C", 5}, {"C", 2}, {"C", 3}, {"C", 4}, {"C", Ace}, {"C", 6}},
+			guest:         Deck{{"H", 5}, {"H", 2}, {"H", 3}, {"H", 4}, {"H", King}},
+			expectedHost:  Deck{{"C", 6}, {"C", 5}, {"H", 5}, {"C", 2}, {"C", 3}, {"C", 4}, {"C", Ace}, {"H", 2}, {"H", 3}, {"H", 4}, {"H", King}},
+			expectedGuest: Deck{},
+			winner:        Host,
+			war: map[string][]Card{
+				"host":  {{"C", 2}, {"C", 3}, {"C", 4}, {"C", Ace}},
+				"guest": {{"H", 2}, {"H", 3}, {"H", 4}, {"H", King}},
+			},
+		},
+		{
+			scenario:      "war with short deck flips last card",
+			ranker:        HighCardRanker{},
+			host:          Deck{{"C", 5}, {"C", 2}},
+			guest:         Deck{{"H", 5}, {"H", 2}, {"H", 3}, {"H", 4}, {"H", King}},
+			expectedHost:  Deck{},
+			expectedGuest: Deck{{"C", 5}, {"H", 5}, {"C", 2}, {"H", 2}, {"H", 3}, {"H", 4}, {"H", King}},
+			winner:        Guest,
+			war: map[string][]Card{
+				"host":  {{"C", 2}},
+				"guest": {{"H", 2}, {"H", 3}, {"H", 4}, {"H", King}},
+			},
+		},
+		{
+			scenario:      "war with empty deck loses",
+			ranker:        HighCardRanker{},
+			host:          Deck{{"C", 5}},
+			guest:         Deck{{"H", 5}, {"H", 2}},
+			expectedHost:  Deck{},
+			expectedGuest: Deck{{"H", 2}, {"C", 5}, {"H", 5}},
+			winner:        Guest,
+			war:           map[string][]Card{},
+		},
+		{
+			scenario:      "tie with no cards left returns played cards",
+			ranker:        HighCardRanker{},
+			host:          Deck{{"C", 5}},
+			guest:         Deck{{"H", 5}},
+			expectedHost:  Deck{{"C", 5}},
+			expectedGuest: Deck{{"H", 5}},
+			winner:        Unknown,
+			war:           map[string][]Card{},
+		},
+		{
+			scenario:      "two beats ace",
+			ranker:        TwoBeatsAceRanker{HighCardRanker{}},
+			host:          Deck{{"C", Ace}},
+			guest:         Deck{{"H", 2}},
+			expectedHost:  Deck{},
+			expectedGuest: Deck{{"C", Ace}, {"H", 2}},
+			winner:        Guest,
+			war:           map[string][]Card{},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.scenario, func(t *testing.T) {
+			host := &Player{Role: Host, Deck: c.host}
+			guest := &Player{Role: Guest, Deck: c.guest}
+			battle := PlayRound([]*Player{host, guest}, c.ranker)
+			assert.Equal(t, c.winner, battle.Winner)
+			assert.Equal(t, c.war, battle.War)
+			assert.Equal(t, c.expectedHost, host.Deck)
+			assert.Equal(t, c.expectedGuest, guest.Deck)
+		})
+	}
+}
+
+func TestPlayRoundSkipsEmptyDecks(t *testing.T) {
+	host := &Player{Role: Host, Deck: Deck{{"C", 5}}}
+	guest := &Player{Role: Guest, Deck: Deck{}}
+	battle := PlayRound([]*Player{host, guest}, HighCardRanker{})
+	assert.Equal(t, Unknown, battle.Winner)
+	assert.Empty(t, battle.Battle)
+	assert.Equal(t, Deck{{"C", 5}}, host.Deck)
+}
+
+func TestGameWinner(t *testing.T) {
+	g := &Game{
+		Player1: &Player{Role: Host, Deck: Deck{{"C", 5}}},
+		Player2: &Player{Role: Guest, Deck: Deck{{"H", 5}}},
+	}
+	assert.Nil(t, g.Winner())
+	g.Player2.Deck = Deck{}
+	assert.Equal(t, g.Player1, g.Winner())
+}
+
+func TestPlayRoundLog(t *testing.T) {
+	testCases := []struct {
+		scenario string
+		ranker   Ranker
+		host     Deck
+		guest    Deck
+		expected []string
+	}{
+		{
+			scenario: "battle",
+			ranker:   HighCardRanker{},
+			host:     Deck{{"C", King}},
+			guest:    Deck{{"H", 10}},
+			expected: []string{
+				"host flips King of Clubs",
+				"guest flips Ten of Hearts",
+				"King of Clubs beats Ten of Hearts",
+				"host wins 2 cards",
+			},
+		},
+		{
+			scenario: "suit breaks tie",
+			ranker:   Rules{Variant: VariantClassic, SuitOrder: DefaultSuitOrder}.Ranker(),
+			host:     Deck{{"H", 7}},
+			guest:    Deck{{"S", 7}},
+			expected: []string{
+				"host flips Seven of Hearts",
+				"guest flips Seven of Spades",
+				"Seven of Spades beats Seven of Hearts, Spades outrank Hearts",
+				"guest wins 2 cards",
+			},
+		},
+		{
+			scenario: "war",
+			ranker:   HighCardRanker{},
+			host:     Deck{{"H", 7}, {"H", 2}, {"H", 3}},
+			guest:    Deck{{"S", 7}, {"S", 2}},
+			expected: []string{
+				"host flips Seven of Hearts",
+				"guest flips Seven of Spades",
+				"War!",
+				"host places 1 card face down and flips Three of Hearts",
+				"guest places 0 cards face down and flips Two of Spades",
+				"Three of Hearts beats Two of Spades",
+				"host wins 5 cards",
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.scenario, func(t *testing.T) {
+			host := &Player{Role: Host, Deck: c.host}
+			guest := &Player{Role: Guest, Deck: c.guest}
+			battle := PlayRound([]*Player{host, guest}, c.ranker)
+			assert.Equal(t, c.expected, battle.Log)
+		})
+	}
+}
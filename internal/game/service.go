@@ -0,0 +1,220 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GameStore keeps games between moves. Implementations must be safe for
+// concurrent use.
+type GameStore interface {
+	// CreateGame saves a new game, and sets its ID and Code.
+	//
+	// CreateGame and SaveGame save nothing, and return ErrCardsNotConserved,
+	// unless the players hold exactly the game's deck (see Game.CheckCards).
+	CreateGame(ctx context.Context, g *Game) error
+	// GetGame returns the game with the ID, or ErrGameNotFound.
+	GetGame(ctx context.Context, id int) (*Game, error)
+	// GetGameIDByCode returns the ID of the game with the code, or
+	// ErrGameNotFound.
+	GetGameIDByCode(ctx context.Context, code string) (int, error)
+	// SaveGame saves the players of a game returned by GetGame, and the round in
+	// its Battle, if any, and increments its Version. Nothing is saved when
+	// another save of the game happened since it was read, which returns
+	// ErrConflict.
+	SaveGame(ctx context.Context, g *Game) error
+	// GetRounds returns every round played in the game, oldest first. Only the
+	// cards first played, the winner, and the log are kept for each round.
+	GetRounds(ctx context.Context, id int) ([]*Battle, error)
+}
+
+// DefaultIdleTimeout is how long the actor of a game stays running without
+// commands, unless the GameService says otherwise.
+const DefaultIdleTimeout = 5 * time.Minute
+
+// GameService plays the games kept in a GameStore, and tells subscribers about
+// every change. Web handlers, RPCs, and tests all play through it.
+//
+// Each game being played is owned by an actor goroutine, which keeps the game in
+// memory and applies its joins and moves in order, saving the game after each.
+type GameService struct {
+	store  GameStore
+	logger *slog.Logger
+	events *broker
+	// NewShuffler returns the shuffler dealing each new game.
+	NewShuffler func() Shuffler
+	// IdleTimeout is how long the actor of a game stays running without
+	// commands. It must be set before the service is used.
+	IdleTimeout time.Duration
+
+	mu     sync.Mutex
+	actors map[int]*actor
+}
+
+// NewGameService returns a GameService keeping games in the store, dealing them
+// with a RiffleShuffler.
+func NewGameService(store GameStore, logger *slog.Logger) *GameService {
+	return &GameService{
+		store:       store,
+		logger:      logger,
+		events:      newBroker(),
+		NewShuffler: func() Shuffler { return NewRiffleShuffler() },
+		IdleTimeout: DefaultIdleTimeout,
+		actors:      make(map[int]*actor),
+	}
+}
+
+// ParseGameID returns the game ID in raw, or ErrGameNotFound when it is not one.
+func ParseGameID(raw string) (int, error) {
+	id, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to convert gameID '%s' to int: %w", ErrGameNotFound, raw, err)
+	}
+	return id, nil
+}
+
+// CreateGame deals and saves a new game with the rules, hosted by the session,
+// with the guest seat controlled as described by seating.
+func (s *GameService) CreateGame(ctx context.Context, sessionID string, rules Rules, seating Seating) (*Game, error) {
+	if err := seating.Validate(); err != nil {
+		return nil, err
+	}
+	g := NewGame(rules, seating, sessionID, s.NewShuffler())
+	if err := s.store.CreateGame(ctx, g); err != nil {
+		return nil, fmt.Errorf("failed to create new game: %w", err)
+	}
+	s.logger.Info("Created new game",
+		"gameID", g.ID,
+		"gameCode", g.Code,
+		"variant", rules.Variant,
+		"suitOrder", rules.SuitOrder,
+		"handSize", rules.HandSize,
+		"guestBot", seating.GuestBot,
+		"hotSeat", seating.HotSeat)
+	return g, nil
+}
+
+// GetGame returns the game with the ID, or ErrGameNotFound. Games being played
+// are returned by their actor, and others are read from the store.
+func (s *GameService) GetGame(ctx context.Context, id int) (*Game, error) {
+	s.mu.Lock()
+	_, ok := s.actors[id]
+	s.mu.Unlock()
+	if ok {
+		return s.do(ctx, id, nil)
+	}
+	return s.store.GetGame(ctx, id)
+}
+
+// GetRounds returns every round played in the game, oldest first.
+func (s *GameService) GetRounds(ctx context.Context, id int) ([]*Battle, error) {
+	if _, err := s.store.GetGame(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.store.GetRounds(ctx, id)
+}
+
+// JoinGame seats the session in the open guest seat of the game with the code.
+// Joining a game the session is already seated at returns the game unchanged.
+func (s *GameService) JoinGame(ctx context.Context, code string, sessionID string) (*Game, error) {
+	id, err := s.store.GetGameIDByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	var joined bool
+	g, err := s.do(ctx, id, func(g *Game) ([]Event, error) {
+		joined = false
+		for _, p := range g.Players() {
+			if p.SessionID == sessionID {
+				return nil, nil
+			}
+		}
+		if g.HotSeat || g.Player2 == nil || g.Player2.SessionID != "" || g.Player2.Bot != "" {
+			return nil, ErrSeatTaken
+		}
+		g.Player2.SessionID = sessionID
+		joined = true
+		return []Event{{Type: EventJoined, GameID: g.ID, Role: Guest}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if joined {
+		s.logger.Info("Joined game",
+			"gameID", g.ID,
+			"sessionID", sessionID)
+	}
+	return g, nil
+}
+
+// move applies the move to the session's seat for the role (see Game.SeatFor),
+// lets any bots move, plays the round once everyone has moved, and saves the
+// game. The returned Game carries the Battle played, if any.
+func (s *GameService) move(ctx context.Context, id int, sessionID string, role GameRole, apply func(*Game, *Player) error) (*Game, error) {
+	g, err := s.do(ctx, id, func(g *Game) ([]Event, error) {
+		if g.Over() {
+			return nil, ErrGameOver
+		}
+		seat, err := g.SeatFor(sessionID, role)
+		if err != nil {
+			return nil, err
+		}
+		if err = g.Play(seat, apply); err != nil {
+			return nil, err
+		}
+		events := []Event{{Type: EventMoved, GameID: g.ID, Role: seat.Role}}
+		if g.Battle != nil {
+			events = append(events, Event{Type: EventRound, GameID: g.ID, Battle: g.Battle})
+		}
+		return events, nil
+	})
+	if err != nil {
+		return g, err
+	}
+	if g.Battle != nil {
+		s.logger.Info("Played round",
+			"gameID", g.ID,
+			"variant", g.Rules.Variant,
+			"winner", g.Battle.Winner)
+	}
+	return g, nil
+}
+
+// Flip marks the session's seat for the role as flipped. Once every seat has
+// flipped, a round is played with the game's Rules. The returned Game carries
+// the Battle played, if any.
+func (s *GameService) Flip(ctx context.Context, id int, sessionID string, role GameRole) (*Game, error) {
+	return s.move(ctx, id, sessionID, role, func(g *Game, seat *Player) error {
+		return g.FlipFor(seat)
+	})
+}
+
+// Choose secretly commits the session's seat for the role to playing the card
+// with the slug from its hand. Once every player holding cards has chosen, the
+// choices are revealed and a round is played with the game's Rules. The
+// returned Game carries the Battle played, if any.
+func (s *GameService) Choose(ctx context.Context, id int, sessionID string, role GameRole, slug string) (*Game, error) {
+	card, err := ParseCard(slug)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMove, err)
+	}
+	nonce, err := NewNonce()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create nonce: %w", err)
+	}
+	return s.move(ctx, id, sessionID, role, func(g *Game, seat *Player) error {
+		return g.ChooseFor(seat, card, nonce)
+	})
+}
+
+// Subscribe returns a channel receiving every Event for the game played through
+// the service, and a function that cancels the subscription and closes the
+// channel.
+func (s *GameService) Subscribe(id int) (<-chan Event, func()) {
+	return s.events.subscribe(id)
+}
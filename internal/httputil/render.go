@@ -0,0 +1,141 @@
+package httputil
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Representation is a way of responding to a request.
+type Representation int
+
+const (
+	// Page is a whole HTML document.
+	Page Representation = iota
+	// Partial is the HTML fragment an htmx request swaps into the page.
+	Partial
+	// JSON is a JSON document, for API clients.
+	JSON
+)
+
+// Negotiate picks the Representation for the response to r. Requests preferring
+// application/json over text/html in their Accept header get JSON, and other htmx
+// requests get a Partial, except when htmx restores its history or boosts a link,
+// which need the whole Page.
+func Negotiate(r *http.Request) Representation {
+	accept := r.Header.Get("Accept")
+	if quality(accept, "application/json") > quality(accept, "text/html") {
+		return JSON
+	}
+	if r.Header.Get("HX-Request") == "true" &&
+		r.Header.Get("HX-History-Restore-Request") != "true" &&
+		r.Header.Get("HX-Boosted") != "true" {
+		return Partial
+	}
+	return Page
+}
+
+// quality returns the weight the Accept header gives the media type, using the
+// most specific range matching it. A missing header accepts everything.
+func quality(accept, mediaType string) float64 {
+	if strings.TrimSpace(accept) == "" {
+		return 1
+	}
+	typ, _, _ := strings.Cut(mediaType, "/")
+	best, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		rng, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		var s int
+		switch rng {
+		case mediaType:
+			s = 2
+		case typ + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		default:
+			continue
+		}
+		if s <= specificity {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		best, specificity = q, s
+	}
+	return best
+}
+
+// Fragment is a template rendering part of a page.
+type Fragment struct {
+	Template string
+	Data     any
+}
+
+// View is what a handler responds with, in each Representation.
+type View struct {
+	Template *template.Template
+	// Page names the template rendering the whole page with Data.
+	Page string
+	Data any
+	// Partials are the fragments htmx requests may target, keyed by the ID of the
+	// target element. Partials[""] is rendered for any other target, and the
+	// whole page when there is no such fragment.
+	Partials map[string]Fragment
+	// JSON is the body for clients preferring JSON. They are sent 406 Not
+	// Acceptable when it is nil.
+	JSON any
+}
+
+// Render writes the view in the Representation negotiated for r, with the status.
+// Nothing is written when the view fails to render, so the caller may still
+// respond with an error.
+func Render(w http.ResponseWriter, r *http.Request, status int, v View) error {
+	const vary = "Accept, HX-Request, HX-Target"
+
+	var buf bytes.Buffer
+	switch Negotiate(r) {
+	case JSON:
+		if v.JSON == nil {
+			w.Header().Add("Vary", vary)
+			http.Error(w, "JSON is not available for this resource", http.StatusNotAcceptable)
+			return nil
+		}
+		if err := json.NewEncoder(&buf).Encode(v.JSON); err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+	case Partial:
+		f, ok := v.Partials[r.Header.Get("HX-Target")]
+		if !ok {
+			f, ok = v.Partials[""]
+		}
+		if !ok {
+			f = Fragment{Template: v.Page, Data: v.Data}
+		}
+		if err := v.Template.ExecuteTemplate(&buf, f.Template, f.Data); err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	default:
+		if err := v.Template.ExecuteTemplate(&buf, v.Page, v.Data); err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+	w.Header().Add("Vary", vary)
+	w.WriteHeader(status)
+	_, err := buf.WriteTo(w)
+	return err
+}
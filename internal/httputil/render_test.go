@@ -0,0 +1,93 @@
+package httputil
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    Representation
+	}{
+		{"no headers", nil, Page},
+		{"browser", map[string]string{"Accept": "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"}, Page},
+		{"anything", map[string]string{"Accept": "*/*"}, Page},
+		{"json", map[string]string{"Accept": "application/json"}, JSON},
+		{"json preferred", map[string]string{"Accept": "text/html;q=0.5, application/json"}, JSON},
+		{"html preferred", map[string]string{"Accept": "application/json;q=0.5, text/*"}, Page},
+		{"json refused", map[string]string{"Accept": "application/json;q=0, */*"}, Page},
+		{"htmx", map[string]string{"HX-Request": "true", "Accept": "*/*"}, Partial},
+		{"htmx json", map[string]string{"HX-Request": "true", "Accept": "application/json"}, JSON},
+		{"htmx history", map[string]string{"HX-Request": "true", "HX-History-Restore-Request": "true"}, Page},
+		{"htmx boosted", map[string]string{"HX-Request": "true", "HX-Boosted": "true"}, Page},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			assert.Equal(t, tt.want, Negotiate(r))
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tmpl := template.Must(template.New("page").Parse(
+		`{{define "page"}}<html>{{template "main" .}}</html>{{end}}` +
+			`{{define "main"}}<main>{{.}}</main>{{end}}` +
+			`{{define "item"}}<li>{{.}}</li>{{end}}`))
+	view := View{
+		Template: tmpl,
+		Page:     "page",
+		Data:     "hello",
+		Partials: map[string]Fragment{
+			"":     {Template: "main", Data: "hello"},
+			"item": {Template: "item", Data: "one"},
+		},
+		JSON: map[string]string{"greeting": "hello"},
+	}
+
+	tests := []struct {
+		name    string
+		view    View
+		headers map[string]string
+		status  int
+		body    string
+	}{
+		{"page", view, nil, http.StatusCreated, "<html><main>hello</main></html>"},
+		{"partial", view, map[string]string{"HX-Request": "true", "HX-Target": "item"}, http.StatusCreated, "<li>one</li>"},
+		{"default partial", view, map[string]string{"HX-Request": "true", "HX-Target": "other"}, http.StatusCreated, "<main>hello</main>"},
+		{"no partials", View{Template: tmpl, Page: "page", Data: "hi"}, map[string]string{"HX-Request": "true"}, http.StatusCreated, "<html><main>hi</main></html>"},
+		{"json", view, map[string]string{"Accept": "application/json"}, http.StatusCreated, "{\"greeting\":\"hello\"}\n"},
+		{"no json", View{Template: tmpl, Page: "page"}, map[string]string{"Accept": "application/json"}, http.StatusNotAcceptable, "JSON is not available for this resource\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			require.NoError(t, Render(w, r, http.StatusCreated, tt.view))
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.body, w.Body.String())
+			assert.Contains(t, w.Header().Values("Vary"), "Accept, HX-Request, HX-Target")
+		})
+	}
+
+	t.Run("template error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		err := Render(w, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, View{Template: tmpl, Page: "missing"})
+		assert.Error(t, err)
+		assert.Empty(t, w.Body.String())
+		assert.Empty(t, w.Header())
+	})
+}
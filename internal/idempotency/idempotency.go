@@ -0,0 +1,262 @@
+// Package idempotency replays the response to a request retried with the same
+// Idempotency-Key header, instead of handling the request again.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/db"
+	"github.com/seanjh/war/internal/session"
+)
+
+// Header is the request header carrying the key, a random string chosen by the
+// client for each request it may retry.
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set on responses replayed for a retried request.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// TTL is how long a key and its response are kept.
+const TTL = 24 * time.Hour
+
+// SweepInterval is how often Sweep deletes expired keys.
+const SweepInterval = time.Hour
+
+const maxKeyLength = 255
+
+// savedHeaders are the response headers replayed with the saved response. Cookies
+// are not replayed, since they may hold credentials.
+var savedHeaders = []string{"Content-Type", "Vary", "Location", "Hx-Push-Url"}
+
+// replayable reports whether a response with the status is saved for replay. Only
+// successes, and client errors that a retry of the same request always gets
+// again, are saved. A conflict or rate limit may clear up, so its request can
+// be retried with the same key.
+func replayable(status int) bool {
+	switch status {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusMethodNotAllowed,
+		http.StatusGone, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return true
+	}
+	return status >= 200 && status < 300
+}
+
+// recorder copies the status and body written to a response.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *recorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// WithKeyMiddleware handles each request carrying an Idempotency-Key once,
+// saving its response for TTL. Retries with the key get the saved response,
+// or a conflict while the first request is still being handled. Responses that
+// are not saved, like server errors and conflicts, release the key, so the
+// request can be retried with it.
+//
+// Keys are scoped to the session of the request, which must be loaded by an
+// outer session.WithSessionMiddleware. A key first used without a session moves
+// to the session its response opened, so it can be retried with that session.
+// Expired keys are reused, and deleted by Sweep.
+func WithKeyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxKeyLength {
+			http.Error(w, "idempotency key is too long", http.StatusBadRequest)
+			return
+		}
+		ctx := appcontext.GetAppContext(r)
+		query := ctx.DBWriter.Query
+		sessionID := session.GetSession(r).ID
+
+		now := time.Now()
+		err := query.DeleteIdempotencyKeyIfExpired(r.Context(), db.DeleteIdempotencyKeyIfExpiredParams{
+			SessionID: sessionID,
+			Key:       key,
+			Expires:   now.Unix(),
+		})
+		if err != nil {
+			ctx.Logger.Error("Failed to delete expired idempotency key",
+				"err", err,
+				"sessionID", sessionID)
+			http.Error(w, "failed to check idempotency key", http.StatusInternalServerError)
+			return
+		}
+		created, err := query.CreateIdempotencyKey(r.Context(), db.CreateIdempotencyKeyParams{
+			SessionID: sessionID,
+			Key:       key,
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			Expires:   now.Add(TTL).Unix(),
+		})
+		if err != nil {
+			ctx.Logger.Error("Failed to create idempotency key",
+				"err", err,
+				"sessionID", sessionID)
+			http.Error(w, "failed to check idempotency key", http.StatusInternalServerError)
+			return
+		}
+		if created == 0 {
+			replay(w, r, key, sessionID)
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w}
+		saved := false
+		defer func() {
+			if saved {
+				return
+			}
+			// Release the key of a failed request, even when it panicked or was
+			// canceled, so it can be retried.
+			err := query.DeleteIdempotencyKey(context.WithoutCancel(r.Context()), db.DeleteIdempotencyKeyParams{
+				SessionID: sessionID,
+				Key:       key,
+			})
+			if err != nil {
+				ctx.Logger.Error("Failed to release idempotency key",
+					"err", err,
+					"sessionID", sessionID)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		if !replayable(rec.status) {
+			return
+		}
+
+		header := make(http.Header)
+		for _, name := range savedHeaders {
+			if values := w.Header().Values(name); len(values) > 0 {
+				header[name] = values
+			}
+		}
+		encoded, err := json.Marshal(header)
+		if err != nil {
+			ctx.Logger.Error("Failed to encode response headers", "err", err)
+			return
+		}
+		body := rec.body.Bytes()
+		if body == nil {
+			body = []byte{}
+		}
+		savedSessionID := sessionID
+		if sessionID == "" {
+			if opened := session.ResponseSessionID(w.Header()); opened != "" {
+				savedSessionID = opened
+			}
+		}
+		err = query.CompleteIdempotencyKey(context.WithoutCancel(r.Context()), db.CompleteIdempotencyKeyParams{
+			NewSessionID: savedSessionID,
+			Status:       int64(rec.status),
+			Header:       string(encoded),
+			Body:         body,
+			SessionID:    sessionID,
+			Key:          key,
+		})
+		if err != nil {
+			ctx.Logger.Error("Failed to save idempotent response",
+				"err", err,
+				"sessionID", sessionID)
+			return
+		}
+		saved = true
+	})
+}
+
+// replay writes the response saved for the key.
+func replay(w http.ResponseWriter, r *http.Request, key string, sessionID string) {
+	ctx := appcontext.GetAppContext(r)
+	row, err := ctx.DBWriter.Query.GetIdempotencyKey(r.Context(), db.GetIdempotencyKeyParams{
+		SessionID: sessionID,
+		Key:       key,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// The first request failed and released the key after this one found it.
+		http.Error(w, "request failed, try again", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		ctx.Logger.Error("Failed to load idempotency key",
+			"err", err,
+			"sessionID", sessionID)
+		http.Error(w, "failed to check idempotency key", http.StatusInternalServerError)
+		return
+	}
+	if row.Method != r.Method || row.Path != r.URL.Path {
+		http.Error(w, "idempotency key was used for another request", http.StatusUnprocessableEntity)
+		return
+	}
+	if row.Status == 0 {
+		http.Error(w, "request is already being handled", http.StatusConflict)
+		return
+	}
+
+	var header http.Header
+	if err := json.Unmarshal([]byte(row.Header), &header); err != nil {
+		ctx.Logger.Error("Failed to decode saved response headers",
+			"err", err,
+			"sessionID", sessionID)
+		http.Error(w, "failed to replay response", http.StatusInternalServerError)
+		return
+	}
+	for name, values := range header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(int(row.Status))
+	if _, err := w.Write(row.Body); err != nil {
+		ctx.Logger.Error("Failed to replay response",
+			"err", err,
+			"sessionID", sessionID)
+	}
+}
+
+// Sweep deletes expired keys every interval until the context is done.
+func Sweep(ctx context.Context, query *db.Queries, logger *slog.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := query.DeleteExpiredIdempotencyKeys(ctx, now.Unix()); err != nil {
+				logger.Error("Failed to delete expired idempotency keys", "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/db"
+	"github.com/seanjh/war/internal/db/dbtest"
+	"github.com/seanjh/war/internal/session"
+)
+
+// newTestHandler returns the handler wrapped by the middleware, behind the
+// session middleware, with a migrated in-memory database holding the sessions
+// "s1" and "s2".
+func newTestHandler(t *testing.T, handler http.Handler) (http.Handler, *sql.DB) {
+	t.Helper()
+	conn := dbtest.Open(t)
+	_, err := conn.Exec(`INSERT INTO sessions (id) VALUES ('s1'), ('s2')`)
+	require.NoError(t, err)
+
+	d := &appcontext.AppContextDB{DB: conn, Query: db.New(conn)}
+	app := &appcontext.AppContext{
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		DBReader: d,
+		DBWriter: d,
+	}
+	return app.Middleware(session.WithSessionMiddleware(WithKeyMiddleware(handler))), conn
+}
+
+// counter responds with the number of requests it has handled.
+type counter struct {
+	n      atomic.Int64
+	status int
+}
+
+func (c *counter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := c.n.Add(1)
+	w.Header().Set("hx-push-url", "/game/1")
+	w.Header().Set("X-Ignored", "1")
+	http.SetCookie(w, &http.Cookie{Name: "other", Value: "secret"})
+	w.WriteHeader(c.status)
+	fmt.Fprintf(w, "request %d", n)
+}
+
+func send(h http.Handler, sessionID, path, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	if sessionID != "" {
+		req.AddCookie(&http.Cookie{Name: "session-id", Value: sessionID})
+	}
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestReplay(t *testing.T) {
+	testCases := []struct {
+		scenario  string
+		status    int
+		sessionID string
+		path      string
+		key       string
+		expected  int
+		body      string
+		replayed  bool
+	}{
+		{
+			scenario:  "duplicate",
+			status:    http.StatusOK,
+			sessionID: "s1",
+			path:      "/game/1/flip",
+			key:       "k",
+			expected:  http.StatusOK,
+			body:      "request 1",
+			replayed:  true,
+		},
+		{
+			scenario:  "duplicate client error",
+			status:    http.StatusBadRequest,
+			sessionID: "s1",
+			path:      "/game/1/flip",
+			key:       "k",
+			expected:  http.StatusBadRequest,
+			body:      "request 1",
+			replayed:  true,
+		},
+		{
+			scenario:  "conflict",
+			status:    http.StatusConflict,
+			sessionID: "s1",
+			path:      "/game/1/flip",
+			key:       "k",
+			expected:  http.StatusConflict,
+			body:      "request 2",
+		},
+		{
+			scenario:  "too many requests",
+			status:    http.StatusTooManyRequests,
+			sessionID: "s1",
+			path:      "/game/1/flip",
+			key:       "k",
+			expected:  http.StatusTooManyRequests,
+			body:      "request 2",
+		},
+		{
+			scenario:  "server error",
+			status:    http.StatusInternalServerError,
+			sessionID: "s1",
+			path:      "/game/1/flip",
+			key:       "k",
+			expected:  http.StatusInternalServerError,
+			body:      "request 2",
+		},
+		{
+			scenario:  "no key",
+			status:    http.StatusOK,
+			sessionID: "s1",
+			path:      "/game/1/flip",
+			expected:  http.StatusOK,
+			body:      "request 2",
+		},
+		{
+			scenario:  "other key",
+			status:    http.StatusOK,
+			sessionID: "s1",
+			path:      "/game/1/flip",
+			key:       "other",
+			expected:  http.StatusOK,
+			body:      "request 2",
+		},
+		{
+			scenario:  "other path",
+			status:    http.StatusOK,
+			sessionID: "s1",
+			path:      "/game/2/flip",
+			key:       "k",
+			expected:  http.StatusUnprocessableEntity,
+		},
+		{
+			scenario:  "other session",
+			status:    http.StatusOK,
+			sessionID: "s2",
+			path:      "/game/1/flip",
+			key:       "k",
+			expected:  http.StatusOK,
+			body:      "request 2",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			c := &counter{status: tc.status}
+			h, _ := newTestHandler(t, c)
+			first := send(h, "s1", "/game/1/flip", "k")
+			require.Equal(t, tc.status, first.Code)
+
+			w := send(h, tc.sessionID, tc.path, tc.key)
+			assert.Equal(t, tc.expected, w.Code)
+			if tc.body != "" {
+				assert.Equal(t, tc.body, w.Body.String())
+			}
+			if tc.replayed {
+				assert.Equal(t, int64(1), c.n.Load())
+				assert.Equal(t, "true", w.Header().Get(ReplayedHeader))
+				assert.Equal(t, "/game/1", w.Header().Get("hx-push-url"))
+				assert.Empty(t, w.Header().Get("X-Ignored"))
+				assert.Empty(t, w.Header().Values("Set-Cookie"))
+			} else {
+				assert.Empty(t, w.Header().Get(ReplayedHeader))
+			}
+		})
+	}
+}
+
+func TestReplayToNewSession(t *testing.T) {
+	var n atomic.Int64
+	h, _ := newTestHandler(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := session.OpenNewSession(w, r)
+		require.NoError(t, err)
+		fmt.Fprintf(w, "request %d", n.Add(1))
+	}))
+	first := send(h, "", "/game", "k")
+	require.Equal(t, http.StatusOK, first.Code)
+	opened := session.ResponseSessionID(first.Header())
+	require.NotEmpty(t, opened)
+
+	w := send(h, opened, "/game", "k")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "request 1", w.Body.String())
+	assert.Empty(t, w.Header().Values("Set-Cookie"))
+
+	// Another session choosing the same key is not answered with the response.
+	w = send(h, "s1", "/game", "k")
+	assert.Equal(t, "request 2", w.Body.String())
+}
+
+func TestReplayWhileHandling(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	h, _ := newTestHandler(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- send(h, "s1", "/game/1/flip", "k") }()
+	<-started
+
+	assert.Equal(t, http.StatusConflict, send(h, "s1", "/game/1/flip", "k").Code)
+	close(release)
+	assert.Equal(t, http.StatusOK, (<-done).Code)
+}
+
+func TestExpiredKey(t *testing.T) {
+	c := &counter{status: http.StatusOK}
+	h, conn := newTestHandler(t, c)
+	require.Equal(t, http.StatusOK, send(h, "s1", "/game/1/flip", "k").Code)
+	_, err := conn.Exec(`UPDATE idempotency_keys SET expires = ?`, time.Now().Unix())
+	require.NoError(t, err)
+
+	w := send(h, "s1", "/game/1/flip", "k")
+	assert.Equal(t, "request 2", w.Body.String())
+	assert.Empty(t, w.Header().Get(ReplayedHeader))
+}
+
+func TestSweep(t *testing.T) {
+	h, conn := newTestHandler(t, &counter{status: http.StatusOK})
+	require.Equal(t, http.StatusOK, send(h, "s1", "/game/1/flip", "k").Code)
+	require.Equal(t, http.StatusOK, send(h, "s2", "/game/1/flip", "k").Code)
+	_, err := conn.Exec(`UPDATE idempotency_keys SET expires = ? WHERE session_id = 's1'`, time.Now().Unix())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		Sweep(ctx, db.New(conn), slog.New(slog.NewTextHandler(io.Discard, nil)), time.Millisecond)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		var n int
+		require.NoError(t, conn.QueryRow(`SELECT count(*) FROM idempotency_keys`).Scan(&n))
+		return n == 1
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	var sessionID string
+	require.NoError(t, conn.QueryRow(`SELECT session_id FROM idempotency_keys`).Scan(&sessionID))
+	assert.Equal(t, "s2", sessionID)
+}
@@ -0,0 +1,72 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+)
+
+// Problem describes a single inconsistency found in the stored ledger.
+type Problem struct {
+	PostingKey string
+	Account    Account
+	Detail     string
+}
+
+func (p Problem) String() string {
+	if p.Account != "" {
+		return fmt.Sprintf("account %s: %s", p.Account, p.Detail)
+	}
+	return fmt.Sprintf("posting %s: %s", p.PostingKey, p.Detail)
+}
+
+// Check scans every stored entry and returns the problems found. A consistent
+// ledger has balanced postings, one kind, session, and game per posting, and no
+// negative balances outside of the house account.
+func (l *Ledger) Check(ctx context.Context) ([]Problem, error) {
+	problems := make([]Problem, 0)
+
+	unbalanced, err := l.reader.Query.ListUnbalancedLedgerPostings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unbalanced postings: %w", err)
+	}
+	for _, row := range unbalanced {
+		problems = append(problems, Problem{
+			PostingKey: row.PostingKey,
+			Detail:     fmt.Sprintf("entries sum to %d", row.Total),
+		})
+	}
+
+	inconsistent, err := l.reader.Query.ListInconsistentLedgerPostings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inconsistent postings: %w", err)
+	}
+	for _, key := range inconsistent {
+		problems = append(problems, Problem{
+			PostingKey: key,
+			Detail:     "entries disagree on kind, session, or game, or lack a counterpart",
+		})
+	}
+
+	balances, err := l.reader.Query.ListLedgerBalances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list balances: %w", err)
+	}
+	var total int64
+	for _, row := range balances {
+		total += row.Balance
+		if row.Balance < 0 && Account(row.Account) != HouseAccount {
+			problems = append(problems, Problem{
+				Account: Account(row.Account),
+				Detail:  fmt.Sprintf("negative balance %d", row.Balance),
+			})
+		}
+	}
+	if total != 0 {
+		problems = append(problems, Problem{
+			Account: "*",
+			Detail:  fmt.Sprintf("all balances sum to %d", total),
+		})
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,231 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/db"
+)
+
+// Kind describes why chips moved in a transaction.
+type Kind string
+
+const (
+	KindGrant  Kind = "grant"
+	KindBet    Kind = "bet"
+	KindPayout Kind = "payout"
+)
+
+// Account identifies a chip balance. Every chip held by a player, staked in a game,
+// or minted by the house lives in exactly one account.
+type Account string
+
+// HouseAccount is the source of granted chips. It is the only account allowed to
+// carry a negative balance.
+const HouseAccount Account = "house"
+
+// SessionAccount returns the account holding the chips owned by a session.
+func SessionAccount(sessionID string) Account {
+	return Account(fmt.Sprintf("session:%s", sessionID))
+}
+
+// PotAccount returns the account holding the chips staked in a game.
+func PotAccount(gameID int64) Account {
+	return Account(fmt.Sprintf("game:%d:pot", gameID))
+}
+
+// Entry is one leg of a transaction. Positive amounts credit the account, and
+// negative amounts debit it.
+type Entry struct {
+	Account Account
+	Amount  int64
+}
+
+// Transaction is a balanced set of entries posted atomically under a unique key.
+// Posting the same transaction twice with the same key has no further effect.
+type Transaction struct {
+	Key       string
+	Kind      Kind
+	SessionID string
+	GameID    int64
+	Entries   []Entry
+}
+
+var (
+	ErrMissingKey        = errors.New("transaction is missing a posting key")
+	ErrUnknownKind       = errors.New("transaction kind is not recognized")
+	ErrTooFewEntries     = errors.New("transaction must have at least 2 entries")
+	ErrZeroAmount        = errors.New("transaction entry amount must not be zero")
+	ErrDuplicateAccount  = errors.New("transaction entries must use distinct accounts")
+	ErrUnbalanced        = errors.New("transaction entries must sum to zero")
+	ErrKeyConflict       = errors.New("posting key was already used for a different transaction")
+	ErrInsufficientFunds = errors.New("account balance is too low")
+)
+
+// Grant moves amount chips from the house to the session.
+func Grant(key, sessionID string, gameID int64, amount int64) Transaction {
+	return Transaction{
+		Key:       key,
+		Kind:      KindGrant,
+		SessionID: sessionID,
+		GameID:    gameID,
+		Entries: []Entry{
+			{Account: HouseAccount, Amount: -amount},
+			{Account: SessionAccount(sessionID), Amount: amount},
+		},
+	}
+}
+
+// Bet moves amount chips from the session into the game pot.
+func Bet(key, sessionID string, gameID int64, amount int64) Transaction {
+	return Transaction{
+		Key:       key,
+		Kind:      KindBet,
+		SessionID: sessionID,
+		GameID:    gameID,
+		Entries: []Entry{
+			{Account: SessionAccount(sessionID), Amount: -amount},
+			{Account: PotAccount(gameID), Amount: amount},
+		},
+	}
+}
+
+// Payout moves amount chips from the game pot to the session.
+func Payout(key, sessionID string, gameID int64, amount int64) Transaction {
+	return Transaction{
+		Key:       key,
+		Kind:      KindPayout,
+		SessionID: sessionID,
+		GameID:    gameID,
+		Entries: []Entry{
+			{Account: PotAccount(gameID), Amount: -amount},
+			{Account: SessionAccount(sessionID), Amount: amount},
+		},
+	}
+}
+
+// Validate reports whether the transaction is well-formed and balanced.
+func (t Transaction) Validate() error {
+	if t.Key == "" {
+		return ErrMissingKey
+	}
+	switch t.Kind {
+	case KindGrant, KindBet, KindPayout:
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownKind, t.Kind)
+	}
+	if len(t.Entries) < 2 {
+		return ErrTooFewEntries
+	}
+	var sum int64
+	seen := make(map[Account]bool, len(t.Entries))
+	for _, e := range t.Entries {
+		if e.Amount == 0 {
+			return fmt.Errorf("%w: account %s", ErrZeroAmount, e.Account)
+		}
+		if seen[e.Account] {
+			return fmt.Errorf("%w: account %s", ErrDuplicateAccount, e.Account)
+		}
+		seen[e.Account] = true
+		sum += e.Amount
+	}
+	if sum != 0 {
+		return fmt.Errorf("%w: sum=%d", ErrUnbalanced, sum)
+	}
+	return nil
+}
+
+// Ledger posts transactions to, and reads balances from, the ledger_entries table.
+type Ledger struct {
+	reader *appcontext.AppContextDB
+	writer *appcontext.AppContextDB
+}
+
+func New(reader, writer *appcontext.AppContextDB) *Ledger {
+	return &Ledger{reader: reader, writer: writer}
+}
+
+// Post records every entry of the transaction atomically. Reposting a transaction
+// whose key was already used is a no-op when the entries match, and fails with
+// ErrKeyConflict when they do not. Debits that would leave any account other than
+// the house below zero fail with ErrInsufficientFunds.
+func (l *Ledger) Post(ctx context.Context, t Transaction) error {
+	if err := t.Validate(); err != nil {
+		return fmt.Errorf("invalid transaction '%s': %w", t.Key, err)
+	}
+
+	tx, err := l.writer.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin posting '%s': %w", t.Key, err)
+	}
+	defer tx.Rollback()
+	query := l.writer.Query.WithTx(tx)
+
+	existing, err := query.GetLedgerEntriesByPostingKey(ctx, t.Key)
+	if err != nil {
+		return fmt.Errorf("failed to load posting '%s': %w", t.Key, err)
+	}
+	if len(existing) > 0 {
+		if !matches(t, existing) {
+			return fmt.Errorf("%w: '%s'", ErrKeyConflict, t.Key)
+		}
+		return nil
+	}
+
+	for _, e := range t.Entries {
+		if e.Amount < 0 && e.Account != HouseAccount {
+			balance, err := query.GetLedgerBalance(ctx, string(e.Account))
+			if err != nil {
+				return fmt.Errorf("failed to load balance for '%s': %w", e.Account, err)
+			}
+			if balance+e.Amount < 0 {
+				return fmt.Errorf("%w: account %s has %d, needs %d",
+					ErrInsufficientFunds, e.Account, balance, -e.Amount)
+			}
+		}
+		err = query.CreateLedgerEntry(ctx, db.CreateLedgerEntryParams{
+			PostingKey: t.Key,
+			Kind:       string(t.Kind),
+			Account:    string(e.Account),
+			SessionID:  t.SessionID,
+			GameID:     t.GameID,
+			Amount:     e.Amount,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post entry for '%s': %w", t.Key, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit posting '%s': %w", t.Key, err)
+	}
+	return nil
+}
+
+func matches(t Transaction, rows []db.GetLedgerEntriesByPostingKeyRow) bool {
+	if len(rows) != len(t.Entries) {
+		return false
+	}
+	for _, row := range rows {
+		if row.Kind != string(t.Kind) || row.SessionID != t.SessionID || row.GameID != t.GameID {
+			return false
+		}
+		want := Entry{Account: Account(row.Account), Amount: row.Amount}
+		if !slices.Contains(t.Entries, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// Balance returns the sum of every entry posted to the account.
+func (l *Ledger) Balance(ctx context.Context, a Account) (int64, error) {
+	balance, err := l.reader.Query.GetLedgerBalance(ctx, string(a))
+	if err != nil {
+		return 0, fmt.Errorf("failed to load balance for '%s': %w", a, err)
+	}
+	return balance, nil
+}
@@ -0,0 +1,154 @@
+package ledger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/db"
+	"github.com/seanjh/war/internal/db/dbtest"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		scenario string
+		tx       Transaction
+		expected error
+	}{
+		{
+			scenario: "grant",
+			tx:       Grant("k", "s1", 1, 100),
+		},
+		{
+			scenario: "bet",
+			tx:       Bet("k", "s1", 1, 10),
+		},
+		{
+			scenario: "payout",
+			tx:       Payout("k", "s1", 1, 20),
+		},
+		{
+			scenario: "missing key",
+			tx:       Grant("", "s1", 1, 100),
+			expected: ErrMissingKey,
+		},
+		{
+			scenario: "unknown kind",
+			tx:       Transaction{Key: "k", Kind: "steal", Entries: Grant("k", "s1", 1, 1).Entries},
+			expected: ErrUnknownKind,
+		},
+		{
+			scenario: "single entry",
+			tx:       Transaction{Key: "k", Kind: KindGrant, Entries: []Entry{{HouseAccount, 0}}},
+			expected: ErrTooFewEntries,
+		},
+		{
+			scenario: "zero amount",
+			tx:       Grant("k", "s1", 1, 0),
+			expected: ErrZeroAmount,
+		},
+		{
+			scenario: "duplicate account",
+			tx: Transaction{Key: "k", Kind: KindBet, Entries: []Entry{
+				{SessionAccount("s1"), -5},
+				{SessionAccount("s1"), 5},
+			}},
+			expected: ErrDuplicateAccount,
+		},
+		{
+			scenario: "unbalanced",
+			tx: Transaction{Key: "k", Kind: KindBet, Entries: []Entry{
+				{SessionAccount("s1"), -5},
+				{PotAccount(1), 6},
+			}},
+			expected: ErrUnbalanced,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.scenario, func(t *testing.T) {
+			err := c.tx.Validate()
+			if c.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, c.expected)
+			}
+		})
+	}
+}
+
+func openTestLedger(t *testing.T) *Ledger {
+	t.Helper()
+	conn := dbtest.Open(t)
+
+	_, err := conn.Exec(`INSERT INTO sessions (id) VALUES ('s1'), ('s2'); INSERT INTO games (id) VALUES (1);`)
+	require.NoError(t, err)
+
+	d := &appcontext.AppContextDB{DB: conn, Query: db.New(conn)}
+	return New(d, d)
+}
+
+func TestPost(t *testing.T) {
+	ctx := context.Background()
+	l := openTestLedger(t)
+
+	require.NoError(t, l.Post(ctx, Grant("grant-1", "s1", 1, 100)))
+	require.NoError(t, l.Post(ctx, Bet("bet-1", "s1", 1, 30)))
+	require.NoError(t, l.Post(ctx, Payout("payout-1", "s1", 1, 30)))
+
+	t.Run("idempotent repost", func(t *testing.T) {
+		assert.NoError(t, l.Post(ctx, Bet("bet-1", "s1", 1, 30)))
+		balance, err := l.Balance(ctx, SessionAccount("s1"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), balance)
+	})
+
+	t.Run("conflicting repost", func(t *testing.T) {
+		assert.ErrorIs(t, l.Post(ctx, Bet("bet-1", "s1", 1, 40)), ErrKeyConflict)
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		assert.ErrorIs(t, l.Post(ctx, Bet("bet-2", "s2", 1, 1)), ErrInsufficientFunds)
+		assert.ErrorIs(t, l.Post(ctx, Payout("payout-2", "s2", 1, 1)), ErrInsufficientFunds)
+	})
+
+	t.Run("balances", func(t *testing.T) {
+		for account, expected := range map[Account]int64{
+			HouseAccount:         -100,
+			SessionAccount("s1"): 100,
+			SessionAccount("s2"): 0,
+			PotAccount(1):        0,
+		} {
+			balance, err := l.Balance(ctx, account)
+			require.NoError(t, err)
+			assert.Equal(t, expected, balance, account)
+		}
+	})
+
+	t.Run("consistent", func(t *testing.T) {
+		problems, err := l.Check(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, problems)
+	})
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	l := openTestLedger(t)
+
+	_, err := l.writer.DB.Exec(`INSERT INTO ledger_entries (posting_key, kind, account, session_id, game_id, amount)
+		VALUES ('bad', 'bet', 'session:s1', 's1', 1, -5), ('bad', 'payout', 'game:1:pot', 's1', 1, 4)`)
+	require.NoError(t, err)
+
+	problems, err := l.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Problem{
+		{PostingKey: "bad", Detail: "entries sum to -1"},
+		{PostingKey: "bad", Detail: "entries disagree on kind, session, or game, or lack a counterpart"},
+		{Account: "session:s1", Detail: "negative balance -5"},
+		{Account: "*", Detail: "all balances sum to -1"},
+	}, problems)
+}
@@ -0,0 +1,142 @@
+// Package offline plays practice games against a bot without a server, for the
+// WebAssembly build that runs in the browser. Games use the same engine, rules,
+// and shuffler as games played on the server.
+package offline
+
+import (
+	"fmt"
+
+	"github.com/seanjh/war/internal/bot"
+	"github.com/seanjh/war/internal/game"
+)
+
+// player is the session owning the host seat of every practice game.
+const player = "offline"
+
+// Options describe a practice game. The opponent defaults to the random bot.
+type Options struct {
+	Variant   string `json:"variant"`
+	HandSize  int    `json:"hand_size"`
+	SuitOrder string `json:"suit_order"`
+	Opponent  string `json:"opponent"`
+}
+
+// Practice is a game between the player, in the host seat, and a bot in the guest
+// seat. The bots must be registered with bot.Register.
+type Practice struct {
+	game *game.Game
+	// last is the most recent round played.
+	last *game.Battle
+}
+
+// New deals a practice game with the options, shuffled by the shuffler.
+func New(opts Options, s game.Shuffler) (*Practice, error) {
+	rules, err := game.NewRules(opts.Variant, opts.HandSize, opts.SuitOrder)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Opponent == "" {
+		opts.Opponent = bot.NameRandom
+	}
+	seating := game.Seating{GuestBot: opts.Opponent}
+	if err := seating.Validate(); err != nil {
+		return nil, err
+	}
+	return &Practice{game: game.NewGame(rules, seating, player, s)}, nil
+}
+
+func (p *Practice) play(move func(*game.Game, *game.Player) error) error {
+	if err := p.game.Play(p.game.Player1, move); err != nil {
+		return err
+	}
+	if p.game.Battle != nil {
+		p.last = p.game.Battle
+	}
+	return nil
+}
+
+// Flip flips the top card of the player's deck, and plays the round once the bot
+// has moved.
+func (p *Practice) Flip() error {
+	return p.play(func(g *game.Game, seat *game.Player) error {
+		return g.FlipFor(seat)
+	})
+}
+
+// Choose plays the card with the slug from the player's hand, and plays the round
+// once the bot has moved.
+func (p *Practice) Choose(slug string) error {
+	card, err := game.ParseCard(slug)
+	if err != nil {
+		return fmt.Errorf("%w: %w", game.ErrInvalidMove, err)
+	}
+	nonce, err := game.NewNonce()
+	if err != nil {
+		return fmt.Errorf("failed to create nonce: %w", err)
+	}
+	return p.play(func(g *game.Game, seat *game.Player) error {
+		return g.ChooseFor(seat, card, nonce)
+	})
+}
+
+// Player is a seat at a practice game. Only the player's own hand is shown.
+type Player struct {
+	Role     string   `json:"role"`
+	DeckSize int      `json:"deck_size"`
+	HandSize int      `json:"hand_size"`
+	Hand     []string `json:"hand,omitempty"`
+	Bot      string   `json:"bot,omitempty"`
+}
+
+// Battle is a round played, with the card each role played first.
+type Battle struct {
+	Cards  map[string]string `json:"cards"`
+	Winner string            `json:"winner,omitempty"`
+	Log    []string          `json:"log"`
+}
+
+// View is the practice game as the player sees it.
+type View struct {
+	Variant  string   `json:"variant"`
+	HandSize int      `json:"hand_size"`
+	Players  []Player `json:"players"`
+	// Battle is the most recent round played, if any.
+	Battle *Battle `json:"battle,omitempty"`
+	Winner string  `json:"winner,omitempty"`
+	Drawn  bool    `json:"drawn,omitempty"`
+}
+
+func slugs(d game.Deck) []string {
+	s := make([]string, len(d))
+	for i, c := range d {
+		s[i] = c.Slug()
+	}
+	return s
+}
+
+// View returns the game as the player sees it.
+func (p *Practice) View() View {
+	g := p.game
+	v := View{Variant: g.Rules.Variant.Name(), HandSize: g.Rules.HandSize}
+	for _, seat := range g.Players() {
+		pv := Player{Role: seat.Role.String(), DeckSize: len(seat.Deck), HandSize: len(seat.Hand), Bot: seat.Bot}
+		if seat.SessionID == player {
+			pv.Hand = slugs(seat.Hand)
+		}
+		v.Players = append(v.Players, pv)
+	}
+	if p.last != nil {
+		v.Battle = &Battle{Cards: make(map[string]string, len(p.last.Battle)), Log: p.last.Log}
+		for role, c := range p.last.Battle {
+			v.Battle.Cards[role] = c.Slug()
+		}
+		if p.last.Winner != game.Unknown {
+			v.Battle.Winner = p.last.Winner.String()
+		}
+	}
+	if w := g.Winner(); w != nil {
+		v.Winner = w.Role.String()
+	}
+	v.Drawn = g.Drawn()
+	return v
+}
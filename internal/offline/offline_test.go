@@ -0,0 +1,98 @@
+package offline
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seanjh/war/internal/bot"
+	"github.com/seanjh/war/internal/game"
+)
+
+func TestNew(t *testing.T) {
+	bot.Register()
+
+	tests := []struct {
+		name string
+		opts Options
+		ok   bool
+	}{
+		{"default opponent", Options{Variant: "classic"}, true},
+		{"hand", Options{Variant: "peace", HandSize: 3, Opponent: bot.NameGreedy}, true},
+		{"unknown variant", Options{Variant: "bogus"}, false},
+		{"hand too large", Options{Variant: "classic", HandSize: game.MaxHandSize + 1}, false},
+		{"unknown opponent", Options{Variant: "classic", Opponent: "nope"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(tt.opts, game.NewRiffleShuffler())
+			if !tt.ok {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			v := p.View()
+			require.Len(t, v.Players, 2)
+			assert.Len(t, v.Players[0].Hand, tt.opts.HandSize)
+			assert.Empty(t, v.Players[1].Hand)
+			assert.NotEmpty(t, v.Players[1].Bot)
+			assert.Nil(t, v.Battle)
+		})
+	}
+}
+
+// cards counts the cards held by both players in the view.
+func cards(v View) int {
+	n := 0
+	for _, p := range v.Players {
+		n += p.DeckSize + p.HandSize
+	}
+	return n
+}
+
+// stacked deals the host the lower half of the deck and the guest the higher half,
+// in order of value, so the guest wins every round.
+type stacked struct{}
+
+func (stacked) Shuffle(d game.Deck) game.Deck {
+	sorted := slices.Clone(d)
+	slices.SortStableFunc(sorted, func(a, b game.Card) int {
+		return cmp.Compare(a.Value, b.Value)
+	})
+	half := len(sorted) / 2
+	for i := range half {
+		// Deck.Cut deals odd positions to the host and even positions to the guest.
+		d[2*i+1], d[2*i] = sorted[i], sorted[half+i]
+	}
+	return d
+}
+
+func TestPlayToTheEnd(t *testing.T) {
+	bot.Register()
+
+	for _, opts := range []Options{
+		{Variant: "classic"},
+		{Variant: "classic", HandSize: 3, SuitOrder: "SHDC", Opponent: bot.NameGreedy},
+	} {
+		p, err := New(opts, stacked{})
+		require.NoError(t, err)
+
+		for i := 0; i < 52 && p.View().Winner == ""; i++ {
+			if opts.HandSize == 0 {
+				require.NoError(t, p.Flip())
+			} else {
+				require.NoError(t, p.Choose(p.View().Players[0].Hand[0]))
+			}
+			v := p.View()
+			require.NotNil(t, v.Battle)
+			if v.Winner == "" {
+				assert.Equal(t, 52, cards(v))
+			}
+		}
+		require.Equal(t, game.Guest.String(), p.View().Winner)
+		assert.ErrorIs(t, p.Flip(), game.ErrGameOver)
+	}
+}
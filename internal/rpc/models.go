@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"github.com/seanjh/war/internal/game"
+	warv1 "github.com/seanjh/war/internal/rpc/war/v1"
+)
+
+func slugs(d game.Deck) []string {
+	s := make([]string, len(d))
+	for i, c := range d {
+		s[i] = c.Slug()
+	}
+	return s
+}
+
+// newGame returns the message for the game as seen by the session.
+func newGame(g *game.Game, sessionID string) *warv1.Game {
+	msg := &warv1.Game{
+		Id:   int64(g.ID),
+		Code: g.Code,
+		Rules: &warv1.Rules{
+			Variant:   string(g.Rules.Variant),
+			SuitOrder: g.Rules.SuitOrder.String(),
+			HandSize:  int32(g.Rules.HandSize),
+		},
+		HotSeat: g.HotSeat,
+	}
+	for _, p := range g.Players() {
+		msg.Players = append(msg.Players, newPlayer(g, p, sessionID))
+	}
+	if g.Battle != nil {
+		msg.Battle = newBattle(g.Battle)
+	}
+	if w := g.Winner(); w != nil {
+		msg.Winner = warv1.Role(w.Role)
+	}
+	msg.Drawn = g.Drawn()
+	return msg
+}
+
+// newPlayer returns the message for the player as seen by the session.
+func newPlayer(g *game.Game, p *game.Player, sessionID string) *warv1.Player {
+	msg := &warv1.Player{
+		Role:         warv1.Role(p.Role),
+		DeckSize:     int32(len(p.Deck)),
+		HandSize:     int32(len(p.Hand)),
+		Flipped:      p.Flipped,
+		Moved:        g.HasMoved(p),
+		Commitment:   string(p.Commitment),
+		Bot:          p.Bot,
+		Seated:       p.SessionID != "" || p.Bot != "" || (g.HotSeat && p.Role == game.Guest),
+		Controllable: g.Controls(sessionID, p),
+	}
+	if msg.Controllable {
+		msg.Hand = slugs(p.Hand)
+		if c := p.ChosenCard(); c != nil {
+			msg.Chosen = c.Slug()
+		}
+	}
+	return msg
+}
+
+func newBattle(b *game.Battle) *warv1.Battle {
+	msg := &warv1.Battle{
+		Cards:  make(map[string]string, len(b.Battle)),
+		Winner: warv1.Role(b.Winner),
+		Log:    b.Log,
+	}
+	for r, c := range b.Battle {
+		msg.Cards[r] = c.Slug()
+	}
+	if len(b.War) > 0 {
+		msg.War = make(map[string]*warv1.Deck, len(b.War))
+		for r, d := range b.War {
+			msg.War[r] = &warv1.Deck{Cards: slugs(d)}
+		}
+	}
+	if len(b.Reveals) > 0 {
+		msg.Reveals = make(map[string]*warv1.Reveal, len(b.Reveals))
+		for r, choice := range b.Reveals {
+			msg.Reveals[r] = &warv1.Reveal{Card: choice.Card.Slug(), Nonce: choice.Nonce}
+		}
+	}
+	return msg
+}
+
+var eventTypes = map[game.EventType]warv1.EventType{
+	game.EventJoined: warv1.EventType_EVENT_TYPE_JOINED,
+	game.EventMoved:  warv1.EventType_EVENT_TYPE_MOVED,
+	game.EventRound:  warv1.EventType_EVENT_TYPE_ROUND,
+}
+
+func newEvent(e game.Event) *warv1.Event {
+	msg := &warv1.Event{
+		Type:   eventTypes[e.Type],
+		GameId: int64(e.GameID),
+		Role:   warv1.Role(e.Role),
+	}
+	if e.Battle != nil {
+		msg.Battle = newBattle(e.Battle)
+	}
+	return msg
+}
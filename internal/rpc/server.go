@@ -0,0 +1,188 @@
+// Package rpc serves the WarService over gRPC, sharing the game engine and
+// storage with the HTTP handlers.
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/game"
+	warv1 "github.com/seanjh/war/internal/rpc/war/v1"
+	"github.com/seanjh/war/internal/session"
+)
+
+// SessionMetadataKey names the metadata carrying the session ID of a call.
+const SessionMetadataKey = "session-id"
+
+// NewServer returns a gRPC server for the WarService, using the application
+// context for every call.
+func NewServer(app *appcontext.AppContext) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			return handler(withSession(app.WithContext(ctx)), req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &serverStream{ServerStream: ss, ctx: withSession(app.WithContext(ss.Context()))})
+		}),
+	)
+	warv1.RegisterWarServiceServer(s, &Server{})
+	return s
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// withSession adds the session named by the call metadata to ctx, when it is
+// recognized.
+func withSession(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get(SessionMetadataKey)) == 0 {
+		return ctx
+	}
+	id := md.Get(SessionMetadataKey)[0]
+	app := appcontext.FromContext(ctx)
+	row, err := app.DBReader.Query.GetSession(ctx, id)
+	if err != nil {
+		app.Logger.Error("invalid session ID",
+			"err", err,
+			"sessionID", id)
+		return ctx
+	}
+	return session.WithSession(ctx, row.ID)
+}
+
+// statusError returns the gRPC status for err.
+func statusError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, game.ErrInvalidVariant),
+		errors.Is(err, game.ErrInvalidHandSize),
+		errors.Is(err, game.ErrInvalidSuitOrder),
+		errors.Is(err, game.ErrInvalidSeating),
+		errors.Is(err, game.ErrInvalidMove):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, game.ErrNotSeated):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, game.ErrGameNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, game.ErrGameOver), errors.Is(err, game.ErrSeatTaken):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, game.ErrConflict):
+		return status.Error(codes.Aborted, err.Error())
+	}
+	app := appcontext.FromContext(ctx)
+	app.Logger.Error("RPC failed", "err", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// Server implements the WarService.
+type Server struct {
+	warv1.UnimplementedWarServiceServer
+}
+
+// requireSession returns the call's session, opening a new one when there is none.
+func requireSession(ctx context.Context) (*session.Session, error) {
+	s := session.FromContext(ctx)
+	if s.ID != "" {
+		return s, nil
+	}
+	return session.CreateSession(ctx)
+}
+
+// games returns the service playing the call's games.
+func games(ctx context.Context) *game.GameService {
+	return appcontext.FromContext(ctx).Games
+}
+
+func (Server) CreateGame(ctx context.Context, req *warv1.CreateGameRequest) (*warv1.CreateGameResponse, error) {
+	rules, err := game.NewRules(req.GetRules().GetVariant(), int(req.GetRules().GetHandSize()), req.GetRules().GetSuitOrder())
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	seating := game.Seating{GuestBot: req.GetOpponent(), HotSeat: req.GetHotSeat()}
+	if err := seating.Validate(); err != nil {
+		return nil, statusError(ctx, err)
+	}
+	s, err := requireSession(ctx)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	g, err := games(ctx).CreateGame(ctx, s.ID, rules, seating)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &warv1.CreateGameResponse{Game: newGame(g, s.ID), SessionId: s.ID}, nil
+}
+
+func (Server) JoinGame(ctx context.Context, req *warv1.JoinGameRequest) (*warv1.JoinGameResponse, error) {
+	s, err := requireSession(ctx)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	g, err := games(ctx).JoinGame(ctx, req.GetCode(), s.ID)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &warv1.JoinGameResponse{Game: newGame(g, s.ID), SessionId: s.ID}, nil
+}
+
+func (Server) GetGame(ctx context.Context, req *warv1.GetGameRequest) (*warv1.GetGameResponse, error) {
+	g, err := games(ctx).GetGame(ctx, int(req.GetGameId()))
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &warv1.GetGameResponse{Game: newGame(g, session.FromContext(ctx).ID)}, nil
+}
+
+func (Server) Flip(ctx context.Context, req *warv1.FlipRequest) (*warv1.FlipResponse, error) {
+	s := session.FromContext(ctx)
+	g, err := games(ctx).Flip(ctx, int(req.GetGameId()), s.ID, game.ConvertGameRole(int64(req.GetRole())))
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &warv1.FlipResponse{Game: newGame(g, s.ID)}, nil
+}
+
+func (Server) Choose(ctx context.Context, req *warv1.ChooseRequest) (*warv1.ChooseResponse, error) {
+	s := session.FromContext(ctx)
+	g, err := games(ctx).Choose(ctx, int(req.GetGameId()), s.ID, game.ConvertGameRole(int64(req.GetRole())), req.GetCard())
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &warv1.ChooseResponse{Game: newGame(g, s.ID)}, nil
+}
+
+func (Server) StreamEvents(req *warv1.StreamEventsRequest, stream grpc.ServerStreamingServer[warv1.StreamEventsResponse]) error {
+	ctx := stream.Context()
+	g, err := games(ctx).GetGame(ctx, int(req.GetGameId()))
+	if err != nil {
+		return statusError(ctx, err)
+	}
+	events, cancel := games(ctx).Subscribe(g.ID)
+	defer cancel()
+	// Headers tell the client the subscription is live.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-events:
+			if err := stream.Send(&warv1.StreamEventsResponse{Event: newEvent(e)}); err != nil {
+				return err
+			}
+		}
+	}
+}
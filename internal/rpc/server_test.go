@@ -0,0 +1,160 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/db"
+	"github.com/seanjh/war/internal/db/dbtest"
+	"github.com/seanjh/war/internal/game"
+	warv1 "github.com/seanjh/war/internal/rpc/war/v1"
+	"github.com/seanjh/war/internal/store"
+)
+
+// newTestClient returns a client for a WarService backed by a migrated in-memory
+// database.
+func newTestClient(t *testing.T) warv1.WarServiceClient {
+	t.Helper()
+	conn := dbtest.Open(t)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	d := &appcontext.AppContextDB{DB: conn, Query: db.New(conn)}
+	app := &appcontext.AppContext{
+		Logger:   logger,
+		DBReader: d,
+		DBWriter: d,
+		Games:    game.NewGameService(store.NewSQL(conn, conn), logger),
+	}
+	lis := bufconn.Listen(1 << 20)
+	s := NewServer(app)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { cc.Close() })
+	return warv1.NewWarServiceClient(cc)
+}
+
+func as(sessionID string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), SessionMetadataKey, sessionID)
+}
+
+func TestPlayGame(t *testing.T) {
+	c := newTestClient(t)
+
+	created, err := c.CreateGame(context.Background(), &warv1.CreateGameRequest{
+		Rules: &warv1.Rules{Variant: "peace"},
+	})
+	require.NoError(t, err)
+	host := created.GetSessionId()
+	require.NotEmpty(t, host)
+	g := created.GetGame()
+	assert.Equal(t, "peace", g.GetRules().GetVariant())
+	require.Len(t, g.GetPlayers(), 2)
+	assert.True(t, g.GetPlayers()[0].GetControllable())
+	assert.False(t, g.GetPlayers()[1].GetSeated())
+
+	ctx, cancel := context.WithCancel(as(host))
+	defer cancel()
+	stream, err := c.StreamEvents(ctx, &warv1.StreamEventsRequest{GameId: g.GetId()})
+	require.NoError(t, err)
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	joined, err := c.JoinGame(context.Background(), &warv1.JoinGameRequest{Code: g.GetCode()})
+	require.NoError(t, err)
+	guest := joined.GetSessionId()
+	assert.NotEqual(t, host, guest)
+	assert.True(t, joined.GetGame().GetPlayers()[1].GetControllable())
+
+	_, err = c.Flip(as(host), &warv1.FlipRequest{GameId: g.GetId()})
+	require.NoError(t, err)
+	flipped, err := c.Flip(as(guest), &warv1.FlipRequest{GameId: g.GetId(), Role: warv1.Role_ROLE_GUEST})
+	require.NoError(t, err)
+	battle := flipped.GetGame().GetBattle()
+	require.NotNil(t, battle)
+	assert.Len(t, battle.GetCards(), 2)
+
+	want := []warv1.EventType{
+		warv1.EventType_EVENT_TYPE_JOINED,
+		warv1.EventType_EVENT_TYPE_MOVED,
+		warv1.EventType_EVENT_TYPE_MOVED,
+		warv1.EventType_EVENT_TYPE_ROUND,
+	}
+	for _, typ := range want {
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, typ, resp.GetEvent().GetType())
+		assert.Equal(t, g.GetId(), resp.GetEvent().GetGameId())
+	}
+
+	got, err := c.GetGame(context.Background(), &warv1.GetGameRequest{GameId: g.GetId()})
+	require.NoError(t, err)
+	for _, p := range got.GetGame().GetPlayers() {
+		assert.False(t, p.GetControllable())
+		assert.Empty(t, p.GetHand())
+	}
+}
+
+func TestErrors(t *testing.T) {
+	c := newTestClient(t)
+	created, err := c.CreateGame(context.Background(), &warv1.CreateGameRequest{})
+	require.NoError(t, err)
+	host := created.GetSessionId()
+
+	tests := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{"hand size", func() error {
+			_, err := c.CreateGame(as(host), &warv1.CreateGameRequest{Rules: &warv1.Rules{HandSize: 9}})
+			return err
+		}, codes.InvalidArgument},
+		{"unknown opponent", func() error {
+			_, err := c.CreateGame(as(host), &warv1.CreateGameRequest{Opponent: "nobody"})
+			return err
+		}, codes.InvalidArgument},
+		{"unknown game", func() error {
+			_, err := c.GetGame(as(host), &warv1.GetGameRequest{GameId: 99})
+			return err
+		}, codes.NotFound},
+		{"unknown code", func() error {
+			_, err := c.JoinGame(as(host), &warv1.JoinGameRequest{Code: "nope"})
+			return err
+		}, codes.NotFound},
+		{"not seated", func() error {
+			_, err := c.Flip(context.Background(), &warv1.FlipRequest{GameId: 1})
+			return err
+		}, codes.PermissionDenied},
+		{"invalid move", func() error {
+			_, err := c.Choose(as(host), &warv1.ChooseRequest{GameId: 1, Card: "2C"})
+			return err
+		}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			require.Error(t, err)
+			assert.Equal(t, tt.code, status.Code(err), err)
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/db"
+	"github.com/seanjh/war/internal/db/dbtest"
+	"github.com/seanjh/war/internal/game"
+	"github.com/seanjh/war/internal/idempotency"
+	"github.com/seanjh/war/internal/store"
+)
+
+type flipper struct{}
+
+func (flipper) Autoplay(g *game.Game, p *game.Player) error {
+	return g.FlipFor(p)
+}
+
+// newTestServer returns the game routes backed by a migrated in-memory database,
+// and the session cookie of a session stored in it.
+func newTestServer(t *testing.T) (http.Handler, *appcontext.AppContext, *http.Cookie) {
+	t.Helper()
+	conn := dbtest.Open(t)
+	_, err := conn.Exec(`INSERT INTO sessions (id) VALUES ('test-session')`)
+	require.NoError(t, err)
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(filepath.Join("..", "..")))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	d := &appcontext.AppContextDB{DB: conn, Query: db.New(conn)}
+	ctx := &appcontext.AppContext{
+		Logger:   logger,
+		DBReader: d,
+		DBWriter: d,
+		Games:    game.NewGameService(store.NewSQL(conn, conn), logger),
+	}
+	mux := SetupRoutes(http.NewServeMux(), func(g *game.Game, sessionID string) any {
+		return map[string]any{"id": g.ID, "code": g.Code}
+	})
+	return ctx.Middleware(mux), ctx, &http.Cookie{Name: "session-id", Value: "test-session"}
+}
+
+func post(t *testing.T, h http.Handler, cookie *http.Cookie, path string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func decodeDeck(t *testing.T, data []byte) game.Deck {
+	t.Helper()
+	var d game.Deck
+	require.NoError(t, d.UnmarshalBinary(data))
+	return d
+}
+
+func TestPlayAgainstComputer(t *testing.T) {
+	game.RegisterAutoplayer("test-flipper", flipper{})
+	h, ctx, cookie := newTestServer(t)
+
+	w := post(t, h, cookie, "/game", url.Values{"opponent": {"test-flipper"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "/game/1", w.Header().Get("hx-push-url"))
+	assert.Contains(t, w.Body.String(), "Computer (test-flipper)")
+
+	for range 3 {
+		w = post(t, h, cookie, "/game/1/flip", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "Round winner:")
+		assert.NotContains(t, w.Body.String(), "waiting for opponent")
+	}
+
+	rows, err := ctx.DBReader.Query.GetGameSessions(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	total := 0
+	for _, row := range rows {
+		assert.Zero(t, row.Flipped)
+		total += len(decodeDeck(t, row.Deck))
+	}
+	assert.Equal(t, 52, total)
+	assert.Equal(t, "test-flipper", rows[1].Bot)
+}
+
+func TestPlayAgainstUnknownComputer(t *testing.T) {
+	h, _, cookie := newTestServer(t)
+	w := post(t, h, cookie, "/game", url.Values{"opponent": {"nobody"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateGameWithUnknownVariant(t *testing.T) {
+	h, _, cookie := newTestServer(t)
+	w := post(t, h, cookie, "/game", url.Values{"variant": {"bogus"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTieBreakWithoutSuitOrder(t *testing.T) {
+	h, _, cookie := newTestServer(t)
+	w := post(t, h, cookie, "/game", url.Values{"tie_break": {"on"}, "suit_order": {""}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFlipWaitsForHumanOpponent(t *testing.T) {
+	h, _, cookie := newTestServer(t)
+	w := post(t, h, cookie, "/game", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = post(t, h, cookie, "/game/1/flip", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Flipped, waiting for opponent")
+	assert.NotContains(t, w.Body.String(), "Round winner:")
+}
+
+func get(t *testing.T, h http.Handler, cookie *http.Cookie, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHotSeatFlip(t *testing.T) {
+	h, _, cookie := newTestServer(t)
+	w := post(t, h, cookie, "/game", url.Values{"hot_seat": {"on"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 2, strings.Count(w.Body.String(), "/game/1/flip"))
+
+	w = post(t, h, cookie, "/game/1/flip", url.Values{"role": {"host"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Flipped, waiting for opponent")
+
+	w = post(t, h, cookie, "/game/1/flip", url.Values{"role": {"guest"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Round winner:")
+}
+
+func TestDuplicateFlipIsReplayed(t *testing.T) {
+	h, app, cookie := newTestServer(t)
+	w := post(t, h, cookie, "/game", url.Values{"hot_seat": {"on"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	flip := func(key, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/game/1/flip", strings.NewReader(url.Values{"role": {role}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set(idempotency.Header, key)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	require.Equal(t, http.StatusOK, flip("host-flip", "host").Code)
+	round := flip("guest-flip", "guest")
+	require.Equal(t, http.StatusOK, round.Code, round.Body.String())
+	assert.Contains(t, round.Body.String(), "Round winner:")
+
+	again := flip("guest-flip", "guest")
+	assert.Equal(t, http.StatusOK, again.Code)
+	assert.Equal(t, round.Body.String(), again.Body.String())
+	rounds, err := app.Games.GetRounds(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Len(t, rounds, 1)
+}
+
+func TestHotSeatWithComputer(t *testing.T) {
+	game.RegisterAutoplayer("test-flipper", flipper{})
+	h, _, cookie := newTestServer(t)
+	w := post(t, h, cookie, "/game", url.Values{"hot_seat": {"on"}, "opponent": {"test-flipper"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHotSeatHandoff(t *testing.T) {
+	h, ctx, cookie := newTestServer(t)
+	hand := func(role game.GameRole) game.Deck {
+		rows, err := ctx.DBReader.Query.GetGameSessions(context.Background(), 1)
+		require.NoError(t, err)
+		for _, row := range rows {
+			if game.ConvertGameRole(row.Role) == role {
+				return decodeDeck(t, row.Hand)
+			}
+		}
+		t.Fatalf("no %s seat", role)
+		return nil
+	}
+
+	w := post(t, h, cookie, "/game", url.Values{"hot_seat": {"on"}, "hand_size": {"3"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Pass the device to the host")
+	assert.NotContains(t, w.Body.String(), "/game/1/choose")
+
+	w = get(t, h, cookie, "/game/1?seat=host")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "Pass the device")
+	assert.Equal(t, 3, strings.Count(w.Body.String(), "/game/1/choose"))
+	assert.Contains(t, w.Body.String(), `"role": "host"`)
+	assert.NotContains(t, w.Body.String(), `"role": "guest"`)
+
+	w = post(t, h, cookie, "/game/1/choose", url.Values{"role": {"host"}, "card": {hand(game.Host)[0].Slug()}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Pass the device to the guest")
+	assert.NotContains(t, w.Body.String(), "/game/1/choose")
+
+	w = post(t, h, cookie, "/game/1/choose", url.Values{"role": {"guest"}, "card": {hand(game.Guest)[0].Slug()}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Round winner:")
+	assert.Contains(t, w.Body.String(), "Pass the device to the host")
+	assert.NotContains(t, w.Body.String(), "/game/1/choose")
+}
+
+func TestHotSeatControlsOnlyHostSession(t *testing.T) {
+	h, ctx, cookie := newTestServer(t)
+	_, err := ctx.DBWriter.DB.Exec(`INSERT INTO sessions (id) VALUES ('other-session')`)
+	require.NoError(t, err)
+
+	w := post(t, h, cookie, "/game", url.Values{"hot_seat": {"on"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	other := &http.Cookie{Name: "session-id", Value: "other-session"}
+	w = post(t, h, other, "/game/1/flip", url.Values{"role": {"guest"}})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestJoinGame(t *testing.T) {
+	h, ctx, cookie := newTestServer(t)
+	_, err := ctx.DBWriter.DB.Exec(`INSERT INTO sessions (id) VALUES ('other-session')`)
+	require.NoError(t, err)
+	other := &http.Cookie{Name: "session-id", Value: "other-session"}
+
+	w := post(t, h, cookie, "/game", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var code string
+	require.NoError(t, ctx.DBReader.DB.QueryRow(`SELECT code FROM games WHERE id = 1`).Scan(&code))
+	assert.Contains(t, w.Body.String(), "Game code: "+code)
+
+	w = post(t, h, other, "/game/join", url.Values{"game_code": {"nope"}})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = post(t, h, other, "/game/join", url.Values{"game_code": {code}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "/game/1", w.Header().Get("hx-push-url"))
+
+	w = post(t, h, cookie, "/game/1/flip", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = post(t, h, other, "/game/1/flip", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Round winner:")
+
+	rounds, err := ctx.DBReader.Query.GetGameRounds(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Len(t, rounds, 1)
+}
+
+func TestRenderGameRepresentations(t *testing.T) {
+	h, _, cookie := newTestServer(t)
+	require.Equal(t, http.StatusOK, post(t, h, cookie, "/game", nil).Code)
+
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		contentType string
+		contains    []string
+		excludes    []string
+	}{
+		{"page", nil, "text/html; charset=utf-8",
+			[]string{"<!doctype html>", `id="game"`}, nil},
+		{"htmx game", map[string]string{"HX-Request": "true", "HX-Target": "game"}, "text/html; charset=utf-8",
+			[]string{`<main id="game">`}, []string{"<!doctype html>"}},
+		{"htmx battleground", map[string]string{"HX-Request": "true", "HX-Target": "battleground"}, "text/html; charset=utf-8",
+			[]string{`id="battleground"`}, []string{"<!doctype html>", `id="game"`, `id="player-host"`}},
+		{"htmx player", map[string]string{"HX-Request": "true", "HX-Target": "player-guest"}, "text/html; charset=utf-8",
+			[]string{`id="player-guest"`}, []string{`id="game"`, `id="player-host"`}},
+		{"htmx history restore", map[string]string{"HX-Request": "true", "HX-History-Restore-Request": "true"}, "text/html; charset=utf-8",
+			[]string{"<!doctype html>"}, nil},
+		{"json", map[string]string{"Accept": "application/json"}, "application/json",
+			[]string{`"id":1`}, []string{"<"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/game/1", nil)
+			req.AddCookie(cookie)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			for _, s := range tt.contains {
+				assert.Contains(t, w.Body.String(), s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, w.Body.String(), s)
+			}
+		})
+	}
+}
+
+func TestConcurrentFlipsConserveCards(t *testing.T) {
+	h, ctx, cookie := newTestServer(t)
+	w := post(t, h, cookie, "/game", url.Values{"hot_seat": {"on"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			role := []string{"host", "guest"}[i%2]
+			w := post(t, h, cookie, "/game/1/flip", url.Values{"role": {role}})
+			// Flips losing the race to other flips are refused, never lost.
+			assert.Contains(t, []int{http.StatusOK, http.StatusConflict}, w.Code, w.Body.String())
+		}()
+	}
+	wg.Wait()
+
+	rows, err := ctx.DBReader.Query.GetGameSessions(context.Background(), 1)
+	require.NoError(t, err)
+	total := 0
+	for _, row := range rows {
+		total += len(decodeDeck(t, row.Deck))
+	}
+	assert.Equal(t, 52, total)
+}
@@ -0,0 +1,398 @@
+// Package server serves War games as web pages, played through the
+// game.GameService of the application context.
+package server
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/game"
+	"github.com/seanjh/war/internal/httputil"
+	"github.com/seanjh/war/internal/idempotency"
+	"github.com/seanjh/war/internal/session"
+)
+
+type PlayerContext struct {
+	GameID int
+	Player *game.Player
+	// Controllable is true when the requesting session controls the seat, and may
+	// see its hidden hand.
+	Controllable bool
+	// War holds the cards the player committed to a war this round, ending with
+	// the last card flipped face up.
+	War []game.Card
+}
+
+// WarCard returns the last card the player flipped face up during a war.
+func (c PlayerContext) WarCard() *game.Card {
+	if len(c.War) == 0 {
+		return nil
+	}
+	return &c.War[len(c.War)-1]
+}
+
+type GameContext struct {
+	Code    string
+	Player1 PlayerContext
+	Player2 PlayerContext
+	Rules   game.Rules
+	Battle  *game.Battle
+	Winner  *game.Player
+	Drawn   bool
+	HotSeat bool
+	// Handoff names the seat the shared device must be passed to before any hand
+	// is shown, in hot-seat games that hide hands.
+	Handoff game.GameRole
+}
+
+// newGameContext returns the game as seen by the session. In hot-seat games that
+// hide hands, the shared device shows only the hand of the seat in view, and
+// shows the handoff screen for the next seat to move when no seat is in view.
+func newGameContext(g *game.Game, sessionID string, view game.GameRole) GameContext {
+	data := GameContext{
+		Code:    g.Code,
+		Player1: newPlayerContext(g, g.Player1, sessionID),
+		Player2: newPlayerContext(g, g.Player2, sessionID),
+		Rules:   g.Rules,
+		Battle:  g.Battle,
+		Winner:  g.Winner(),
+		Drawn:   g.Drawn(),
+		HotSeat: g.HotSeat,
+	}
+	if g.Battle != nil {
+		data.Player1.War = g.Battle.War[game.Host.String()]
+		data.Player2.War = g.Battle.War[game.Guest.String()]
+	}
+	if g.HotSeat && g.Rules.HandSize > 0 && !g.Over() {
+		if view == game.Unknown {
+			data.Handoff = g.NextToMove()
+		}
+		for _, pc := range []*PlayerContext{&data.Player1, &data.Player2} {
+			if pc.Player == nil || pc.Player.Role != view {
+				pc.Controllable = false
+			}
+		}
+	}
+	return data
+}
+
+func newPlayerContext(g *game.Game, p *game.Player, sessionID string) PlayerContext {
+	return PlayerContext{
+		GameID:       g.ID,
+		Player:       p,
+		Controllable: p != nil && g.Controls(sessionID, p),
+	}
+}
+
+// Encoder returns the JSON representation of the game as seen by the session.
+type Encoder func(g *game.Game, sessionID string) any
+
+// gameRenderer renders games with the game templates, and as JSON with encode.
+// Requests only accepting JSON get 406 Not Acceptable when encode is nil.
+type gameRenderer struct {
+	tmpl   *template.Template
+	encode Encoder
+}
+
+func newGameRenderer(encode Encoder) gameRenderer {
+	return gameRenderer{tmpl: loadGameTemplates(), encode: encode}
+}
+
+// view returns the game as seen by the session, as a whole page, as the
+// fragments htmx requests target, and as JSON.
+func (gr gameRenderer) view(g *game.Game, sessionID string, view game.GameRole) httputil.View {
+	data := newGameContext(g, sessionID, view)
+	partials := map[string]httputil.Fragment{
+		"":             {Template: "main", Data: data},
+		"game":         {Template: "main", Data: data},
+		"battleground": {Template: "battleground", Data: data},
+	}
+	for _, pc := range []PlayerContext{data.Player1, data.Player2} {
+		if pc.Player != nil {
+			partials["player-"+pc.Player.Role.String()] = httputil.Fragment{Template: "player", Data: pc}
+		}
+	}
+	v := httputil.View{Template: gr.tmpl, Page: "layout", Data: data, Partials: partials}
+	if gr.encode != nil {
+		v.JSON = gr.encode(g, sessionID)
+	}
+	return v
+}
+
+// render writes the game as seen by the session, in the representation
+// negotiated for the request.
+func (gr gameRenderer) render(w http.ResponseWriter, r *http.Request, g *game.Game, sessionID string, view game.GameRole) {
+	if err := httputil.Render(w, r, http.StatusOK, gr.view(g, sessionID, view)); err != nil {
+		ctx := appcontext.GetAppContext(r)
+		ctx.Logger.Error("Failed to render game",
+			"err", err,
+			"gameID", g.ID,
+			"sessionID", sessionID)
+		http.Error(w, "failed to render game", http.StatusInternalServerError)
+	}
+}
+
+func loadGameTemplates() *template.Template {
+	return template.Must(template.ParseFiles(
+		filepath.Join("templates", "layout.html"),
+		filepath.Join("templates", "game.html"),
+		filepath.Join("templates", "player.html"),
+		filepath.Join("templates", "battleground.html"),
+		filepath.Join("templates", "warzone.html"),
+	))
+}
+
+func CreateAndRenderGame(encode Encoder) http.HandlerFunc {
+	gr := newGameRenderer(encode)
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := appcontext.GetAppContext(r)
+
+		handSize := 0
+		if raw := r.FormValue("hand_size"); raw != "" {
+			var err error
+			if handSize, err = strconv.Atoi(raw); err != nil {
+				http.Error(w, game.ErrInvalidHandSize.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		suitOrder := ""
+		if r.FormValue("tie_break") != "" {
+			// An empty suit order means no tie-break, so it is refused
+			// rather than quietly playing ties as wars.
+			if suitOrder = r.FormValue("suit_order"); suitOrder == "" {
+				http.Error(w, game.ErrInvalidSuitOrder.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		rules, err := game.NewRules(r.FormValue("variant"), handSize, suitOrder)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		seating := game.Seating{
+			GuestBot: r.FormValue("opponent"),
+			HotSeat:  r.FormValue("hot_seat") != "",
+		}
+		if err := seating.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s := session.GetSession(r)
+		if s.ID == "" {
+			newSession, err := session.OpenNewSession(w, r)
+			s = newSession
+			if err != nil {
+				ctx.Logger.Info("Failed to open new session",
+					"err", err,
+				)
+				http.Error(w, "Failed to create new session", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		g, err := ctx.Games.CreateGame(r.Context(), s.ID, rules, seating)
+		if err != nil {
+			ctx.Logger.Error("Failed to create new game", "err", err)
+			http.Error(w, "Failed to create new game", http.StatusInternalServerError)
+			return
+		}
+		ctx.Logger.Info("Created new game and host game session",
+			"gameID", g.ID,
+		)
+		w.Header().Add("hx-push-url", fmt.Sprintf("/game/%d", g.ID))
+		gr.render(w, r, g, s.ID, game.Unknown)
+	}
+}
+
+func JoinAndRenderGame(encode Encoder) http.HandlerFunc {
+	gr := newGameRenderer(encode)
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := appcontext.GetAppContext(r)
+
+		s := session.GetSession(r)
+		if s.ID == "" {
+			newSession, err := session.OpenNewSession(w, r)
+			s = newSession
+			if err != nil {
+				ctx.Logger.Info("Failed to open new session",
+					"err", err,
+				)
+				http.Error(w, "Failed to create new session", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		g, err := ctx.Games.JoinGame(r.Context(), r.FormValue("game_code"), s.ID)
+		switch {
+		case errors.Is(err, game.ErrGameNotFound):
+			http.Error(w, "no game with that code", http.StatusNotFound)
+			return
+		case errors.Is(err, game.ErrSeatTaken):
+			http.Error(w, "game is full", http.StatusConflict)
+			return
+		case errors.Is(err, game.ErrConflict):
+			http.Error(w, "game was changed by another player, try again", http.StatusConflict)
+			return
+		case err != nil:
+			ctx.Logger.Error("Failed to join game", "err", err)
+			http.Error(w, "Failed to join game", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("hx-push-url", fmt.Sprintf("/game/%d", g.ID))
+		gr.render(w, r, g, s.ID, game.Unknown)
+	}
+}
+
+func RenderGame(encode Encoder) http.HandlerFunc {
+	gr := newGameRenderer(encode)
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		ctx := appcontext.GetAppContext(r)
+
+		s := session.GetSession(r)
+		if s.ID == "" {
+			ctx.Logger.Error("missing required session for game",
+				"gameID", id,
+				"sessionID", s.ID)
+			http.Error(w, "cannot locate game", http.StatusBadRequest)
+			return
+		}
+
+		g, err := loadGame(r)
+		if err != nil {
+			ctx.Logger.Error("failed to load game from database",
+				"err", err,
+				"sessionID", s.ID,
+				"gameID", id)
+			http.Error(w, "cannot locate game", http.StatusBadRequest)
+			return
+		}
+
+		gr.render(w, r, g, s.ID, game.ParseGameRole(r.URL.Query().Get("seat")))
+	}
+}
+
+// loadGame returns the game named by the request path.
+func loadGame(r *http.Request) (*game.Game, error) {
+	id, err := game.ParseGameID(r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	return appcontext.GetAppContext(r).Games.GetGame(r.Context(), id)
+}
+
+// move plays a move in the game named by the request path.
+func move(r *http.Request, play func(games *game.GameService, id int) (*game.Game, error)) (*game.Game, error) {
+	id, err := game.ParseGameID(r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	return play(appcontext.GetAppContext(r).Games, id)
+}
+
+// renderMove renders the game after a move, or the error that prevented it.
+func (gr gameRenderer) renderMove(w http.ResponseWriter, r *http.Request, g *game.Game, err error) {
+	id := r.PathValue("id")
+	ctx := appcontext.GetAppContext(r)
+	s := session.GetSession(r)
+
+	switch {
+	case errors.Is(err, game.ErrNotSeated):
+		http.Error(w, "not a player in this game", http.StatusForbidden)
+		return
+	case errors.Is(err, game.ErrGameOver):
+		http.Error(w, "game is over", http.StatusConflict)
+		return
+	case errors.Is(err, game.ErrConflict):
+		http.Error(w, "game was changed by another move, try again", http.StatusConflict)
+		return
+	case errors.Is(err, game.ErrGameNotFound):
+		http.Error(w, "cannot locate game", http.StatusNotFound)
+		return
+	case errors.Is(err, game.ErrInvalidMove):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		ctx.Logger.Error("failed to play move",
+			"err", err,
+			"sessionID", s.ID,
+			"gameID", id)
+		http.Error(w, "failed to play move", http.StatusInternalServerError)
+		return
+	}
+	gr.render(w, r, g, s.ID, game.Unknown)
+}
+
+func CreateFlip(encode Encoder) http.HandlerFunc {
+	gr := newGameRenderer(encode)
+	return func(w http.ResponseWriter, r *http.Request) {
+		s := session.GetSession(r)
+		g, err := move(r, func(games *game.GameService, id int) (*game.Game, error) {
+			return games.Flip(r.Context(), id, s.ID, game.ParseGameRole(r.FormValue("role")))
+		})
+		gr.renderMove(w, r, g, err)
+	}
+}
+
+func CreateChoice(encode Encoder) http.HandlerFunc {
+	gr := newGameRenderer(encode)
+	return func(w http.ResponseWriter, r *http.Request) {
+		s := session.GetSession(r)
+		g, err := move(r, func(games *game.GameService, id int) (*game.Game, error) {
+			return games.Choose(r.Context(), id, s.ID, game.ParseGameRole(r.FormValue("role")), r.FormValue("card"))
+		})
+		gr.renderMove(w, r, g, err)
+	}
+}
+
+func RenderHome() http.HandlerFunc {
+	tmpl := template.Must(template.ParseFiles(
+		filepath.Join("templates", "layout.html"),
+		filepath.Join("templates", "home.html"),
+	))
+	return func(w http.ResponseWriter, r *http.Request) {
+		data := struct {
+			Variants    []game.Variant
+			MaxHandSize int
+			Bots        []string
+		}{game.Variants, game.MaxHandSize, game.Autoplayers()}
+		tmpl.ExecuteTemplate(w, "layout", data)
+	}
+}
+
+// RenderOffline renders the practice page, which plays against a bot in the
+// browser with the engine compiled to WebAssembly.
+func RenderOffline() http.HandlerFunc {
+	tmpl := template.Must(template.ParseFiles(
+		filepath.Join("templates", "layout.html"),
+		filepath.Join("templates", "offline.html"),
+	))
+	return func(w http.ResponseWriter, r *http.Request) {
+		data := struct {
+			Variants    []game.Variant
+			MaxHandSize int
+			Bots        []string
+		}{game.Variants, game.MaxHandSize, game.Autoplayers()}
+		tmpl.ExecuteTemplate(w, "layout", data)
+	}
+}
+
+// SetupRoutes adds the pages and game routes to the mux. Game routes respond to
+// requests preferring JSON with the representation returned by encode.
+func SetupRoutes(mux *http.ServeMux, encode Encoder) *http.ServeMux {
+	mux.Handle("GET /", http.HandlerFunc(RenderHome()))
+	mux.Handle("GET /offline", http.HandlerFunc(RenderOffline()))
+	mux.Handle("POST /game", session.WithSessionMiddleware(idempotency.WithKeyMiddleware(CreateAndRenderGame(encode))))
+	mux.Handle("POST /game/join", session.WithSessionMiddleware(JoinAndRenderGame(encode)))
+	mux.Handle("GET /game/{id}", session.WithSessionMiddleware(RenderGame(encode)))
+	mux.Handle("POST /game/{id}/flip", session.WithSessionMiddleware(idempotency.WithKeyMiddleware(CreateFlip(encode))))
+	mux.Handle("POST /game/{id}/choose", session.WithSessionMiddleware(idempotency.WithKeyMiddleware(CreateChoice(encode))))
+	return mux
+}
@@ -3,12 +3,14 @@ package session
 import (
 	"context"
 	"crypto/rand"
+	"database/sql"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/db"
 )
 
 type Session struct {
@@ -52,25 +54,67 @@ func generateSessionID() (string, error) {
 // GetOrCreate returns the session from the request, or generates a new session when none
 // is present.
 func OpenNewSession(w http.ResponseWriter, r *http.Request) (*Session, error) {
-	ctx := appcontext.GetAppContext(r)
+	s, err := CreateSession(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	http.SetCookie(w, cookie(s.ID))
+	return s, nil
+}
+
+// CreateSession stores a new session with a random ID.
+func CreateSession(ctx context.Context) (*Session, error) {
+	app := appcontext.FromContext(ctx)
 	sessionID, err := generateSessionID()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new session ID: %w", err)
 	}
-	ctx.Logger.Info("Generated new random session ID",
+	app.Logger.Info("Generated new random session ID",
 		"sessionID", sessionID)
 
-	dbSess, err := ctx.DBWriter.Query.CreateSession(r.Context(), sessionID)
+	dbSess, err := app.DBWriter.Query.CreateSession(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create a new session: %w", err)
 	}
-	ctx.Logger.Info("Created new session",
+	app.Logger.Info("Created new session",
 		"sessionId", dbSess.ID, "created", dbSess.Created)
-
-	http.SetCookie(w, cookie(dbSess.ID))
 	return &Session{ID: dbSess.ID}, nil
 }
 
+// OpenSSHSession returns the session of the SSH public key with the fingerprint,
+// storing a new session the first time the key is seen.
+func OpenSSHSession(ctx context.Context, fingerprint string) (*Session, error) {
+	app := appcontext.FromContext(ctx)
+	key := sql.NullString{String: fingerprint, Valid: true}
+	row, err := app.DBReader.Query.GetSessionBySSHFingerprint(ctx, key)
+	if err == nil {
+		return &Session{ID: row.ID}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get the session for key %s: %w", fingerprint, err)
+	}
+
+	sessionID, err := generateSessionID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new session ID: %w", err)
+	}
+	created, err := app.DBWriter.Query.CreateSSHSession(ctx, db.CreateSSHSessionParams{
+		ID:             sessionID,
+		SshFingerprint: key,
+	})
+	if err != nil {
+		// Another connection with the key may have stored its session first.
+		row, getErr := app.DBWriter.Query.GetSessionBySSHFingerprint(ctx, key)
+		if getErr != nil {
+			return nil, fmt.Errorf("failed to create a new session for key %s: %w", fingerprint, err)
+		}
+		return &Session{ID: row.ID}, nil
+	}
+	app.Logger.Info("Created new SSH session",
+		"sessionId", created.ID, "fingerprint", fingerprint)
+	return &Session{ID: created.ID}, nil
+}
+
 func cookie(sessionID string) *http.Cookie {
 	// NOTE(sean): maybe switch to gorillatoolkit.org/pkg/securecookie
 	return &http.Cookie{
@@ -83,6 +127,17 @@ func cookie(sessionID string) *http.Cookie {
 	}
 }
 
+// ResponseSessionID returns the ID of the session opened by a response with the
+// header, or "" when it opened none.
+func ResponseSessionID(h http.Header) string {
+	for _, c := range (&http.Response{Header: h}).Cookies() {
+		if c.Name == cookieName {
+			return c.Value
+		}
+	}
+	return ""
+}
+
 const sessionIDKey string = "sessionid"
 
 func WithSessionMiddleware(next http.Handler) http.Handler {
@@ -95,23 +150,38 @@ func WithSessionMiddleware(next http.Handler) http.Handler {
 				"sessionID", rawID)
 			return
 		}
+		if rawID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
 		sessionID, err := validateSessionId(rawID, r)
 		if err != nil {
 			ctx.Logger.Error("invalid session ID",
 				"err", err,
-				"sessionID", sessionID)
+				"sessionID", rawID)
+			next.ServeHTTP(w, r)
 			return
 		}
 		ctx.Logger.Info("loaded session for request",
 			"sessionID", sessionID)
-		c := context.WithValue(r.Context(), sessionIDKey, sessionID)
-		next.ServeHTTP(w, r.WithContext(c))
+		next.ServeHTTP(w, r.WithContext(WithSession(r.Context(), sessionID)))
 	})
 }
 
+// WithSession returns a copy of ctx carrying the ID of a validated session.
+func WithSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionIDKey, sessionID)
+}
+
 func GetSession(r *http.Request) *Session {
+	return FromContext(r.Context())
+}
+
+// FromContext returns the session carried by ctx, whose ID is empty when there is
+// none.
+func FromContext(ctx context.Context) *Session {
 	sess := &Session{}
-	sessionID, ok := r.Context().Value(sessionIDKey).(string)
+	sessionID, ok := ctx.Value(sessionIDKey).(string)
 	if ok {
 		sess.ID = sessionID
 	}
@@ -0,0 +1,67 @@
+package sim
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/seanjh/war/internal/game"
+)
+
+// ShuffleStats counts where the cards of a deck end up after shuffles.
+type ShuffleStats struct {
+	Shuffles int
+	// Counts counts the shuffles moving the card from each position to each
+	// other position, both from the top.
+	Counts [game.DeckCapacity][game.DeckCapacity]int
+}
+
+// Shuffle riffles the StandardDeck, in order, the number of times each shuffle
+// takes, and counts where every card ends up. The same arguments always count
+// the same shuffles.
+func Shuffle(shuffles, riffles int, seed uint64) *ShuffleStats {
+	random := rand.New(rand.NewPCG(seed, seed)).Float32
+	ordered, err := game.PackDeck(game.StandardDeck.Cards())
+	if err != nil {
+		panic(err)
+	}
+	// start is the position of each card in the ordered deck.
+	var start [256]int
+	for i := 0; i < ordered.Len(); i++ {
+		start[ordered.At(i)] = i
+	}
+	stats := &ShuffleStats{Shuffles: shuffles}
+	for i := 0; i < shuffles; i++ {
+		deck := ordered
+		for j := 0; j < riffles; j++ {
+			deck.Riffle(random)
+		}
+		for to := 0; to < deck.Len(); to++ {
+			stats.Counts[start[deck.At(to)]][to]++
+		}
+	}
+	return stats
+}
+
+// Distance returns the total variation distance between where the card starting
+// at the position ended up and a uniform distribution: 0 when it is equally likely
+// to end up anywhere, and nearly 1 when it always ends up in one place.
+func (s *ShuffleStats) Distance(from int) float64 {
+	if s.Shuffles == 0 {
+		return 0
+	}
+	uniform := 1 / float64(game.DeckCapacity)
+	distance := 0.0
+	for _, n := range s.Counts[from] {
+		distance += math.Abs(float64(n)/float64(s.Shuffles) - uniform)
+	}
+	return distance / 2
+}
+
+// MaxDistance returns the largest Distance of any starting position.
+func (s *ShuffleStats) MaxDistance() float64 {
+	worst := 0.0
+	for from := range s.Counts {
+		worst = max(worst, s.Distance(from))
+	}
+	return worst
+}
@@ -0,0 +1,194 @@
+// Package sim plays many games of War without a store or a session, to study how
+// the rules and the shuffler behave. Games are played on packed decks, so they
+// run without allocating.
+package sim
+
+import (
+	"math/rand/v2"
+
+	"github.com/seanjh/war/internal/game"
+)
+
+// warSize is the number of cards each tied player places face down before
+// flipping again during a war, as in game.PlayRound.
+const warSize = 3
+
+// Result is the outcome of a game played by Simulate.
+type Result struct {
+	Rounds int
+	// Wars counts the rounds that went to war.
+	Wars int
+	// Winner is game.Unknown when the game was stopped after the most rounds.
+	Winner game.GameRole
+}
+
+// seat is a player of a simulated game.
+type seat struct {
+	role game.GameRole
+	deck game.PackedDeck
+	// faceUp is the card the player flipped last this round.
+	faceUp game.PackedCard
+}
+
+// Simulate deals the deck between a host and a guest like game.NewGame, and plays
+// rounds like game.PlayRound with the rules' Ranker until a player holds every
+// card, or maxRounds rounds were played. Every game is played by flipping, since
+// the hands of the hand War variant need choices.
+func Simulate(rules game.Rules, deck *game.PackedDeck, maxRounds int) Result {
+	host, guest := &seat{role: game.Host}, &seat{role: game.Guest}
+	for i := 0; i < deck.Len(); i++ {
+		if i&1 == 1 {
+			host.deck.Push(deck.At(i))
+		} else {
+			guest.deck.Push(deck.At(i))
+		}
+	}
+	ranker := rules.Ranker()
+
+	var r Result
+	for r.Rounds < maxRounds {
+		if host.deck.Len() == 0 {
+			r.Winner = game.Guest
+			break
+		}
+		if guest.deck.Len() == 0 {
+			r.Winner = game.Host
+			break
+		}
+		r.Rounds++
+		if playRound(ranker, host, guest) {
+			r.Wars++
+		}
+	}
+	return r
+}
+
+// playRound plays a round between two players holding cards, and reports whether
+// it went to war.
+func playRound(ranker game.Ranker, host, guest *seat) bool {
+	// The pot holds the cards played, in the order they were played, and
+	// whether the host played each one.
+	var pot [game.DeckCapacity]game.PackedCard
+	var byHost [game.DeckCapacity]bool
+	n := 0
+	play := func(s *seat) {
+		c, _ := s.deck.Pop()
+		s.faceUp = c
+		pot[n] = c
+		byHost[n] = s == host
+		n++
+	}
+	award := func(winner *seat) {
+		for _, c := range pot[:n] {
+			winner.deck.Push(c)
+		}
+	}
+
+	play(host)
+	play(guest)
+	war := false
+	for {
+		switch c := ranker.Compare(host.faceUp.Card(), guest.faceUp.Card()); {
+		case c > 0:
+			award(host)
+			return war
+		case c < 0:
+			award(guest)
+			return war
+		}
+		switch {
+		case host.deck.Len() > 0 && guest.deck.Len() == 0:
+			award(host)
+			return war
+		case guest.deck.Len() > 0 && host.deck.Len() == 0:
+			award(guest)
+			return war
+		case host.deck.Len() == 0 && guest.deck.Len() == 0:
+			for i, c := range pot[:n] {
+				if byHost[i] {
+					host.deck.Push(c)
+				} else {
+					guest.deck.Push(c)
+				}
+			}
+			return war
+		}
+		war = true
+		for _, s := range []*seat{host, guest} {
+			for i := min(warSize, s.deck.Len()-1); i >= 0; i-- {
+				play(s)
+			}
+		}
+	}
+}
+
+// Config describes the games played by Play.
+type Config struct {
+	Rules     game.Rules
+	Games     int
+	MaxRounds int
+	// Seed seeds the random numbers shuffling each game's deck.
+	Seed uint64
+}
+
+// Stats sums up the games played by Play.
+type Stats struct {
+	Games     int
+	HostWins  int
+	GuestWins int
+	// Unfinished counts the games stopped after the most rounds.
+	Unfinished int
+	Rounds     int
+	Wars       int
+	// LongestGame is the most rounds played by a finished game.
+	LongestGame int
+}
+
+// Add adds the games summed up by o.
+func (s *Stats) Add(o Stats) {
+	s.Games += o.Games
+	s.HostWins += o.HostWins
+	s.GuestWins += o.GuestWins
+	s.Unfinished += o.Unfinished
+	s.Rounds += o.Rounds
+	s.Wars += o.Wars
+	s.LongestGame = max(s.LongestGame, o.LongestGame)
+}
+
+// MeanRounds returns the mean number of rounds played by each game.
+func (s Stats) MeanRounds() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return float64(s.Rounds) / float64(s.Games)
+}
+
+// Play simulates games dealt from decks shuffled like new games, and sums them
+// up. The same Config always plays the same games.
+func Play(cfg Config) Stats {
+	random := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed)).Float32
+	dealt, err := game.PackDeck(cfg.Rules.Deck().Cards())
+	if err != nil {
+		panic(err)
+	}
+	var stats Stats
+	for i := 0; i < cfg.Games; i++ {
+		deck := dealt
+		deck.Shuffle(random)
+		r := Simulate(cfg.Rules, &deck, cfg.MaxRounds)
+		stats.Games++
+		stats.Rounds += r.Rounds
+		stats.Wars += r.Wars
+		switch r.Winner {
+		case game.Host:
+			stats.HostWins++
+		case game.Guest:
+			stats.GuestWins++
+		default:
+			stats.Unfinished++
+			continue
+		}
+		stats.LongestGame = max(stats.LongestGame, r.Rounds)
+	}
+	return stats
+}
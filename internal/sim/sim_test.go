@@ -0,0 +1,100 @@
+package sim
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seanjh/war/internal/game"
+)
+
+// maxRounds stops the games that cycle without end.
+const maxRounds = 5000
+
+func TestSimulateMatchesPlayRound(t *testing.T) {
+	rulesets := []game.Rules{
+		{Variant: game.VariantClassic},
+		{Variant: game.VariantPeace},
+		{Variant: game.VariantTwoBeatsAce},
+		{Variant: game.VariantClassic, SuitOrder: game.DefaultSuitOrder},
+	}
+	for _, rules := range rulesets {
+		for seed := uint64(0); seed < 20; seed++ {
+			deck, err := game.PackDeck(game.StandardDeck.Cards())
+			require.NoError(t, err)
+			deck.Shuffle(rand.New(rand.NewPCG(seed, seed)).Float32)
+
+			d1, d2 := deck.Deck().Cut()
+			host := &game.Player{Role: game.Host, Deck: d1}
+			guest := &game.Player{Role: game.Guest, Deck: d2}
+			g := &game.Game{Rules: rules, Player1: host, Player2: guest}
+			var want Result
+			for want.Rounds < maxRounds && g.Winner() == nil {
+				b := game.PlayRound(g.Players(), rules.Ranker())
+				want.Rounds++
+				if len(b.War) > 0 {
+					want.Wars++
+				}
+			}
+			if w := g.Winner(); w != nil {
+				want.Winner = w.Role
+			}
+
+			assert.Equal(t, want, Simulate(rules, &deck, maxRounds), "%+v with seed %d", rules, seed)
+		}
+	}
+}
+
+func TestPlay(t *testing.T) {
+	cfg := Config{Rules: game.Rules{Variant: game.VariantClassic}, Games: 200, MaxRounds: maxRounds, Seed: 1}
+	stats := Play(cfg)
+	assert.Equal(t, stats, Play(cfg))
+	assert.Equal(t, 200, stats.Games)
+	assert.Equal(t, stats.Games, stats.HostWins+stats.GuestWins+stats.Unfinished)
+	assert.Greater(t, stats.HostWins, 0)
+	assert.Greater(t, stats.GuestWins, 0)
+	assert.Greater(t, stats.MeanRounds(), 1.0)
+	assert.LessOrEqual(t, stats.LongestGame, maxRounds)
+	assert.NotEqual(t, stats, Play(Config{Rules: cfg.Rules, Games: 200, MaxRounds: maxRounds, Seed: 2}))
+}
+
+func TestShuffle(t *testing.T) {
+	unshuffled := Shuffle(2000, 0, 1)
+	assert.InDelta(t, 1-1.0/game.DeckCapacity, unshuffled.MaxDistance(), 1e-9)
+	for from := range unshuffled.Counts {
+		assert.Equal(t, 2000, unshuffled.Counts[from][from])
+	}
+
+	once := Shuffle(2000, 1, 1)
+	seven := Shuffle(2000, 7, 1)
+	for from := range seven.Counts {
+		total := 0
+		for _, n := range seven.Counts[from] {
+			total += n
+		}
+		assert.Equal(t, 2000, total)
+	}
+	assert.Less(t, once.MaxDistance(), unshuffled.MaxDistance())
+	assert.Less(t, seven.MaxDistance(), once.MaxDistance())
+	assert.Zero(t, Shuffle(0, 7, 1).MaxDistance())
+}
+
+// reportPerMinute reports how many times the benchmark ran its loop per minute.
+func reportPerMinute(b *testing.B, unit string) {
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds()*time.Minute.Seconds(), unit+"/min")
+}
+
+func BenchmarkPlay(b *testing.B) {
+	b.ReportAllocs()
+	Play(Config{Rules: game.Rules{Variant: game.VariantClassic}, Games: b.N, MaxRounds: maxRounds, Seed: 1})
+	reportPerMinute(b, "games")
+}
+
+func BenchmarkShuffle(b *testing.B) {
+	b.ReportAllocs()
+	Shuffle(b.N, 7, 1)
+	reportPerMinute(b, "shuffles")
+}
@@ -0,0 +1,182 @@
+// Package sshserver lets players connect with SSH to play War in a terminal UI.
+// Players are identified by their public key, and play through the JSON API of
+// the server, so they share games with web players.
+package sshserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/session"
+	"github.com/seanjh/war/internal/tui"
+	"github.com/seanjh/war/pkg/warclient"
+)
+
+// fingerprintExtension names the permission carrying the SHA256 fingerprint of the
+// public key a connection authenticated with.
+const fingerprintExtension = "fingerprint"
+
+// Server serves the terminal UI over SSH.
+type Server struct {
+	app     *appcontext.AppContext
+	config  *ssh.ServerConfig
+	baseURL string
+}
+
+// New returns a Server identifying itself with the host key, whose players use
+// the JSON API at baseURL.
+func New(app *appcontext.AppContext, hostKey ssh.Signer, baseURL string) *Server {
+	config := &ssh.ServerConfig{
+		// Any key is accepted, since the key only picks the player's session.
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			return &ssh.Permissions{Extensions: map[string]string{
+				fingerprintExtension: ssh.FingerprintSHA256(key),
+			}}, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+	return &Server{app: app, config: config, baseURL: baseURL}
+}
+
+// Serve accepts connections on lis until it fails.
+func (s *Server) Serve(lis net.Listener) error {
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		s.app.Logger.Info("SSH handshake failed",
+			"err", err,
+			"remoteAddr", conn.RemoteAddr().String())
+		return
+	}
+	defer sconn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	ctx, cancel := context.WithCancel(s.app.WithContext(context.Background()))
+	defer cancel()
+	fingerprint := sconn.Permissions.Extensions[fingerprintExtension]
+	sess, err := session.OpenSSHSession(ctx, fingerprint)
+	if err != nil {
+		s.app.Logger.Error("failed to open SSH session",
+			"err", err,
+			"fingerprint", fingerprint)
+		return
+	}
+	s.app.Logger.Info("SSH connection opened",
+		"sessionID", sess.ID,
+		"user", sconn.User(),
+		"remoteAddr", sconn.RemoteAddr().String())
+
+	for newChan := range chans {
+		if newChan.ChannelType() != "session" {
+			newChan.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		ch, requests, err := newChan.Accept()
+		if err != nil {
+			s.app.Logger.Error("failed to accept SSH channel", "err", err)
+			continue
+		}
+		go s.handleChannel(ctx, sess.ID, ch, requests)
+	}
+}
+
+// ptyRequest is the payload of a "pty-req" request, from RFC 4254 section 6.2.
+type ptyRequest struct {
+	Term          string
+	Columns, Rows uint32
+	Width, Height uint32
+	Modes         string
+}
+
+// windowChange is the payload of a "window-change" request, from RFC 4254
+// section 6.7.
+type windowChange struct {
+	Columns, Rows uint32
+	Width, Height uint32
+}
+
+type exitStatus struct {
+	Status uint32
+}
+
+// handleChannel runs the lobby in the channel once the client asks for a shell.
+// A terminal is required, so shells without a pty are refused.
+func (s *Server) handleChannel(ctx context.Context, sessionID string, ch ssh.Channel, requests <-chan *ssh.Request) {
+	defer ch.Close()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var pty *ptyRequest
+	var p *tea.Program
+	for req := range requests {
+		switch req.Type {
+		case "pty-req":
+			pty = &ptyRequest{}
+			if err := ssh.Unmarshal(req.Payload, pty); err != nil {
+				pty = nil
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+		case "window-change":
+			var size windowChange
+			if err := ssh.Unmarshal(req.Payload, &size); err == nil && p != nil {
+				p.Send(tea.WindowSizeMsg{Width: int(size.Columns), Height: int(size.Rows)})
+			}
+		case "shell":
+			if pty == nil || p != nil {
+				req.Reply(false, nil)
+				if p == nil {
+					fmt.Fprint(ch.Stderr(), "War needs a terminal, so connect with ssh -t.\r\n")
+					return
+				}
+				continue
+			}
+			req.Reply(true, nil)
+			p = s.run(ctx, sessionID, ch)
+			p.Send(tea.WindowSizeMsg{Width: int(pty.Columns), Height: int(pty.Rows)})
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+// run starts the lobby for the session in the channel, and closes the channel
+// when the player quits.
+func (s *Server) run(ctx context.Context, sessionID string, ch ssh.Channel) *tea.Program {
+	client := warclient.New(s.baseURL)
+	client.SetSessionID(sessionID)
+	// Terminals without playing card glyphs are common, so cards are drawn as ASCII.
+	p := tea.NewProgram(tui.NewLobby(ctx, client, true),
+		tea.WithContext(ctx),
+		tea.WithInput(ch),
+		tea.WithOutput(ch),
+		tea.WithAltScreen())
+	go func() {
+		status := exitStatus{}
+		if _, err := p.Run(); err != nil && ctx.Err() == nil {
+			s.app.Logger.Error("SSH terminal UI failed",
+				"err", err,
+				"sessionID", sessionID)
+			status.Status = 1
+		}
+		ch.SendRequest("exit-status", false, ssh.Marshal(&status))
+		ch.Close()
+	}()
+	return p
+}
@@ -0,0 +1,185 @@
+package sshserver
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/seanjh/war/internal/api"
+	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/db"
+	"github.com/seanjh/war/internal/db/dbtest"
+	"github.com/seanjh/war/internal/game"
+	"github.com/seanjh/war/internal/store"
+)
+
+// newTestServer returns the address of an SSH server playing through an API
+// backed by a migrated in-memory database, and the database.
+func newTestServer(t *testing.T) (string, *sql.DB) {
+	t.Helper()
+	conn := dbtest.Open(t)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	d := &appcontext.AppContextDB{DB: conn, Query: db.New(conn)}
+	app := &appcontext.AppContext{
+		Logger:   logger,
+		DBReader: d,
+		DBWriter: d,
+		Games:    game.NewGameService(store.NewSQL(conn, conn), logger),
+	}
+	web := httptest.NewServer(app.Middleware(api.SetupRoutes(http.NewServeMux())))
+	t.Cleanup(web.Close)
+
+	hostKey, err := LoadHostKey(filepath.Join(t.TempDir(), "host_key"))
+	require.NoError(t, err)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { lis.Close() })
+	go New(app, hostKey, web.URL).Serve(lis)
+	return lis.Addr().String(), conn
+}
+
+func newKey(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := ssh.NewSignerFromKey(priv)
+	require.NoError(t, err)
+	return key
+}
+
+func dial(t *testing.T, addr string, key ssh.Signer) *ssh.Client {
+	t.Helper()
+	client, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            "player",
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(key)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// terminal is the output of an SSH session with a pty, safe to read while it is
+// written.
+type terminal struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *terminal) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *terminal) Contains(s string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return bytes.Contains(w.buf.Bytes(), []byte(s))
+}
+
+// play opens a shell, types the keys once the text is shown for each, and waits
+// for the session to end.
+func play(t *testing.T, client *ssh.Client, steps ...[2]string) {
+	t.Helper()
+	s, err := client.NewSession()
+	require.NoError(t, err)
+	defer s.Close()
+	out := &terminal{}
+	s.Stdout = out
+	stdin, err := s.StdinPipe()
+	require.NoError(t, err)
+	require.NoError(t, s.RequestPty("xterm", 40, 100, ssh.TerminalModes{}))
+	require.NoError(t, s.Shell())
+
+	for _, step := range steps {
+		want, keys := step[0], step[1]
+		require.Eventually(t, func() bool { return out.Contains(want) }, 5*time.Second, 10*time.Millisecond, want)
+		_, err := stdin.Write([]byte(keys))
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.Wait())
+}
+
+func TestHostGame(t *testing.T) {
+	addr, conn := newTestServer(t)
+	key := newKey(t)
+
+	play(t, dial(t, addr, key),
+		[2]string{"h host a game", "h"},
+		[2]string{"press f to flip", "q"})
+
+	var sessionID, fingerprint string
+	require.NoError(t, conn.QueryRow(`SELECT id, ssh_fingerprint FROM sessions`).Scan(&sessionID, &fingerprint))
+	assert.Equal(t, ssh.FingerprintSHA256(key.PublicKey()), fingerprint)
+	var host string
+	require.NoError(t, conn.QueryRow(`SELECT session_id FROM game_sessions WHERE role = 1`).Scan(&host))
+	assert.Equal(t, sessionID, host)
+
+	// The key keeps its session across connections.
+	play(t, dial(t, addr, key), [2]string{"h host a game", "q"})
+	var sessions int
+	require.NoError(t, conn.QueryRow(`SELECT COUNT(*) FROM sessions`).Scan(&sessions))
+	assert.Equal(t, 1, sessions)
+}
+
+func TestJoinGame(t *testing.T) {
+	addr, conn := newTestServer(t)
+	host := dial(t, addr, newKey(t))
+	guest := dial(t, addr, newKey(t))
+
+	hostDone := make(chan struct{})
+	go func() {
+		defer close(hostDone)
+		play(t, host,
+			[2]string{"h host a game", "h"},
+			[2]string{"guest (seated)", "q"})
+	}()
+
+	var code string
+	require.Eventually(t, func() bool {
+		return conn.QueryRow(`SELECT code FROM games`).Scan(&code) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	play(t, guest,
+		[2]string{"h host a game", "j"},
+		[2]string{"Game code", code + "\r"},
+		[2]string{"guest (you)", "q"})
+	<-hostDone
+}
+
+func TestShellNeedsTerminal(t *testing.T) {
+	addr, _ := newTestServer(t)
+	s, err := dial(t, addr, newKey(t)).NewSession()
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Error(t, s.Shell())
+}
+
+func TestLoadHostKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "host_key")
+	generated, err := LoadHostKey(path)
+	require.NoError(t, err)
+	loaded, err := LoadHostKey(path)
+	require.NoError(t, err)
+	assert.Equal(t, generated.PublicKey().Marshal(), loaded.PublicKey().Marshal())
+
+	require.NoError(t, os.WriteFile(path, []byte("not a key"), 0o600))
+	_, err = LoadHostKey(path)
+	assert.Error(t, err)
+}
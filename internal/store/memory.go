@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/seanjh/war/internal/game"
+)
+
+// Memory is a game.GameStore keeping games in memory, for tests and servers whose
+// games need not outlive them.
+type Memory struct {
+	mu     sync.Mutex
+	games  map[int]*game.Game
+	codes  map[string]int
+	rounds map[int][]*game.Battle
+}
+
+// NewMemory returns an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		games:  make(map[int]*game.Game),
+		codes:  make(map[string]int),
+		rounds: make(map[int][]*game.Battle),
+	}
+}
+
+// newCode returns a random code like the ones the database generates.
+func newCode() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+func (m *Memory) CreateGame(ctx context.Context, g *game.Game) error {
+	if err := g.CheckCards(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	code, err := newCode()
+	for err == nil && m.codes[code] != 0 {
+		code, err = newCode()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create game code: %w", err)
+	}
+	g.ID = len(m.games) + 1
+	g.Code = code
+	stored := g.Clone()
+	stored.Battle = nil
+	m.games[g.ID] = stored
+	m.codes[code] = g.ID
+	return nil
+}
+
+func (m *Memory) GetGame(ctx context.Context, id int) (*game.Game, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g, ok := m.games[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: gameID '%d'", game.ErrGameNotFound, id)
+	}
+	return g.Clone(), nil
+}
+
+func (m *Memory) GetGameIDByCode(ctx context.Context, code string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id, ok := m.codes[code]
+	if !ok {
+		return 0, fmt.Errorf("%w: code '%s'", game.ErrGameNotFound, code)
+	}
+	return id, nil
+}
+
+func (m *Memory) SaveGame(ctx context.Context, g *game.Game) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.games[g.ID]
+	if !ok {
+		return fmt.Errorf("%w: gameID '%d'", game.ErrGameNotFound, g.ID)
+	}
+	if stored.Version != g.Version {
+		return fmt.Errorf("%w: gameID '%d' version %d", game.ErrConflict, g.ID, g.Version)
+	}
+	if err := g.CheckCards(); err != nil {
+		return err
+	}
+	g.Version++
+	if g.Battle != nil {
+		m.rounds[g.ID] = append(m.rounds[g.ID], &game.Battle{
+			Battle: maps.Clone(g.Battle.Battle),
+			Winner: g.Battle.Winner,
+			Log:    slices.Clone(g.Battle.Log),
+		})
+	}
+	// Like the database, only the players are saved.
+	players := g.Clone()
+	saved := *stored
+	saved.Player1, saved.Player2 = players.Player1, players.Player2
+	saved.Version = g.Version
+	// Like the database, rounds are counted from the rounds saved.
+	saved.Rounds = len(m.rounds[g.ID])
+	m.games[g.ID] = &saved
+	return nil
+}
+
+func (m *Memory) GetRounds(ctx context.Context, id int) ([]*game.Battle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rounds := make([]*game.Battle, len(m.rounds[id]))
+	for i, b := range m.rounds[id] {
+		rounds[i] = &game.Battle{
+			Battle: maps.Clone(b.Battle),
+			Winner: b.Winner,
+			Log:    slices.Clone(b.Log),
+		}
+	}
+	return rounds, nil
+}
@@ -0,0 +1,12 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/seanjh/war/internal/game"
+	"github.com/seanjh/war/internal/store/storetest"
+)
+
+func TestMemory(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) game.GameStore { return NewMemory() })
+}
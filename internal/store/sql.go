@@ -0,0 +1,317 @@
+// Package store keeps War games for a game.GameService.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/seanjh/war/internal/db"
+	"github.com/seanjh/war/internal/game"
+)
+
+// SQL is a game.GameStore keeping games in a database migrated with
+// internal/db/migrations, using the queries generated by sqlc.
+type SQL struct {
+	reader *sql.DB
+	writer *sql.DB
+}
+
+// NewSQL returns a store reading games from reader and saving them to writer,
+// which may be the same database.
+func NewSQL(reader, writer *sql.DB) *SQL {
+	return &SQL{reader: reader, writer: writer}
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func (s *SQL) CreateGame(ctx context.Context, g *game.Game) error {
+	if err := g.CheckCards(); err != nil {
+		return err
+	}
+	tx, err := s.writer.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	query := db.New(tx)
+
+	row, err := query.CreateGame(ctx, db.CreateGameParams{
+		Variant:   string(g.Rules.Variant),
+		SuitOrder: g.Rules.SuitOrder.String(),
+		HandSize:  int64(g.Rules.HandSize),
+		HotSeat:   boolToInt(g.HotSeat),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create game row: %w", err)
+	}
+	host, guest := g.Player1, g.Player2
+	decks, err := marshalDecks(host.Deck, host.Hand, guest.Deck, guest.Hand)
+	if err != nil {
+		return err
+	}
+	err = query.CreateHostGameSession(ctx, db.CreateHostGameSessionParams{
+		GameID:    row.ID,
+		GameID_2:  row.ID,
+		Deck:      decks[0],
+		Hand:      decks[1],
+		Deck_2:    decks[2],
+		Hand_2:    decks[3],
+		Bot:       guest.Bot,
+		SessionID: nullString(host.SessionID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create host game session: %w", err)
+	}
+	if err = checkCards(ctx, query, int(row.ID)); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit new game: %w", err)
+	}
+	g.ID = int(row.ID)
+	g.Code = row.Code
+	return nil
+}
+
+func (s *SQL) GetGame(ctx context.Context, id int) (*game.Game, error) {
+	return getGame(ctx, db.New(s.reader), id)
+}
+
+func getGame(ctx context.Context, query *db.Queries, id int) (*game.Game, error) {
+	gameRow, err := query.GetGame(ctx, int64(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: gameID '%d'", game.ErrGameNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gameID '%d' from database: %w", id, err)
+	}
+	suitOrder, err := game.ConvertSuitOrder(gameRow.SuitOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gameID '%d' suit order: %w", id, err)
+	}
+	g := &game.Game{
+		ID:   id,
+		Code: gameRow.Code,
+		Rules: game.Rules{
+			Variant:   game.ConvertVariant(gameRow.Variant),
+			SuitOrder: suitOrder,
+			HandSize:  int(gameRow.HandSize),
+		},
+		HotSeat: gameRow.HotSeat == 1,
+		Version: int(gameRow.Version),
+		Rounds:  int(gameRow.Rounds),
+	}
+
+	rows, err := query.GetGameSessions(ctx, int64(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sessions for gameID '%d' from database: %w", id, err)
+	}
+	for _, row := range rows {
+		role := game.ConvertGameRole(row.Role)
+		var deck, hand game.Deck
+		if err := deck.UnmarshalBinary(row.Deck); err != nil {
+			return nil, fmt.Errorf("failed to load %s deck for gameID '%d': %w", role, id, err)
+		}
+		if err := hand.UnmarshalBinary(row.Hand); err != nil {
+			return nil, fmt.Errorf("failed to load %s hand for gameID '%d': %w", role, id, err)
+		}
+		player := &game.Player{
+			Role:       role,
+			Deck:       deck,
+			SessionID:  row.SessionID,
+			Flipped:    row.Flipped == 1,
+			Hand:       hand,
+			Commitment: game.Commitment(row.Commitment),
+			Bot:        row.Bot,
+		}
+		if row.Choice != "" {
+			card, err := game.ParseCard(row.Choice)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load %s choice for gameID '%d': %w", role, id, err)
+			}
+			player.RestoreChoice(game.Choice{Card: card, Nonce: row.Nonce})
+		}
+		switch role {
+		case game.Host:
+			g.Player1 = player
+		case game.Guest:
+			g.Player2 = player
+		default:
+			return nil, fmt.Errorf("failed to load gameID '%d': unsupported role %d", id, row.Role)
+		}
+	}
+	return g, nil
+}
+
+func (s *SQL) GetGameIDByCode(ctx context.Context, code string) (int, error) {
+	id, err := db.New(s.reader).GetGameIDByCode(ctx, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: code '%s'", game.ErrGameNotFound, code)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up game code '%s': %w", code, err)
+	}
+	return int(id), nil
+}
+
+// ListGameIDs returns the ID of every stored game, oldest first.
+func (s *SQL) ListGameIDs(ctx context.Context) ([]int, error) {
+	rows, err := db.New(s.reader).ListGameIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list games: %w", err)
+	}
+	ids := make([]int, len(rows))
+	for i, id := range rows {
+		ids[i] = int(id)
+	}
+	return ids, nil
+}
+
+// SaveGame compares and swaps the version of the game row before saving the
+// players. GetGame reads the version before the players, so a game changed while
+// it was read is never saved.
+func (s *SQL) SaveGame(ctx context.Context, g *game.Game) error {
+	if err := g.CheckCards(); err != nil {
+		return err
+	}
+	tx, err := s.writer.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin save: %w", err)
+	}
+	defer tx.Rollback()
+	query := db.New(tx)
+
+	n, err := query.UpdateGameVersion(ctx, db.UpdateGameVersionParams{ID: int64(g.ID), Version: int64(g.Version)})
+	if err != nil {
+		return fmt.Errorf("failed to update gameID '%d' version: %w", g.ID, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: gameID '%d' version %d", game.ErrConflict, g.ID, g.Version)
+	}
+	if g.Battle != nil {
+		if err = saveRound(ctx, query, g.ID, g.Battle); err != nil {
+			return err
+		}
+	}
+	for _, p := range g.Players() {
+		if err = savePlayer(ctx, query, g.ID, p); err != nil {
+			return err
+		}
+	}
+	if err = checkCards(ctx, query, g.ID); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit save: %w", err)
+	}
+	g.Version++
+	return nil
+}
+
+// checkCards reads the game back within the transaction saving it, which is
+// rolled back unless the stored players hold exactly the game's deck. Games are
+// checked before they are written too, but only reading them back also catches
+// cards lost to their encoding.
+func checkCards(ctx context.Context, query *db.Queries, id int) error {
+	g, err := getGame(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	return g.CheckCards()
+}
+
+// marshalDecks encodes each deck as stored, with game.Deck.MarshalBinary.
+func marshalDecks(decks ...game.Deck) ([][]byte, error) {
+	data := make([][]byte, len(decks))
+	for i, d := range decks {
+		var err error
+		if data[i], err = d.MarshalBinary(); err != nil {
+			return nil, fmt.Errorf("failed to encode deck: %w", err)
+		}
+	}
+	return data, nil
+}
+
+func savePlayer(ctx context.Context, query *db.Queries, gameID int, p *game.Player) error {
+	decks, err := marshalDecks(p.Deck, p.Hand)
+	if err != nil {
+		return fmt.Errorf("failed to save %s: %w", p.Role, err)
+	}
+	params := db.UpdateGameSessionParams{
+		SessionID:  nullString(p.SessionID),
+		Deck:       decks[0],
+		Flipped:    boolToInt(p.Flipped),
+		Hand:       decks[1],
+		Commitment: string(p.Commitment),
+		GameID:     int64(gameID),
+		Role:       int64(p.Role),
+	}
+	if c := p.PendingChoice(); c != nil {
+		params.Choice = c.Card.Slug()
+		params.Nonce = c.Nonce
+	}
+	if err := query.UpdateGameSession(ctx, params); err != nil {
+		return fmt.Errorf("failed to save %s: %w", p.Role, err)
+	}
+	return nil
+}
+
+func saveRound(ctx context.Context, query *db.Queries, gameID int, b *game.Battle) error {
+	params := db.CreateGameRoundParams{
+		GameID: int64(gameID),
+		Winner: int64(b.Winner),
+		Log:    strings.Join(b.Log, "\n"),
+	}
+	if c, ok := b.Battle[game.Host.String()]; ok {
+		params.HostCard = c.Slug()
+	}
+	if c, ok := b.Battle[game.Guest.String()]; ok {
+		params.GuestCard = c.Slug()
+	}
+	if err := query.CreateGameRound(ctx, params); err != nil {
+		return fmt.Errorf("failed to save round: %w", err)
+	}
+	return nil
+}
+
+func (s *SQL) GetRounds(ctx context.Context, id int) ([]*game.Battle, error) {
+	rows, err := db.New(s.reader).GetGameRounds(ctx, int64(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rounds for gameID '%d': %w", id, err)
+	}
+	rounds := make([]*game.Battle, 0, len(rows))
+	for _, row := range rows {
+		b := &game.Battle{
+			Battle: make(map[string]game.Card),
+			Winner: game.ConvertGameRole(row.Winner),
+			Log:    make([]string, 0),
+		}
+		for role, slug := range map[game.GameRole]string{game.Host: row.HostCard, game.Guest: row.GuestCard} {
+			if slug == "" {
+				continue
+			}
+			card, err := game.ParseCard(slug)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load round for gameID '%d': %w", id, err)
+			}
+			b.Battle[role.String()] = card
+		}
+		if row.Log != "" {
+			b.Log = strings.Split(row.Log, "\n")
+		}
+		rounds = append(rounds, b)
+	}
+	return rounds, nil
+}
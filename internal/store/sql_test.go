@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seanjh/war/internal/db/dbtest"
+	"github.com/seanjh/war/internal/game"
+	"github.com/seanjh/war/internal/store/storetest"
+)
+
+// newTestSQL returns a store backed by a migrated in-memory database.
+func newTestSQL(t *testing.T) *SQL {
+	t.Helper()
+	conn := dbtest.Open(t)
+	for _, id := range []string{storetest.Host, storetest.Guest} {
+		_, err := conn.Exec(`INSERT INTO sessions (id) VALUES (?)`, id)
+		require.NoError(t, err)
+	}
+	return NewSQL(conn, conn)
+}
+
+func TestSQL(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) game.GameStore { return newTestSQL(t) })
+}
+
+// migrateToBinaryDecks returns a database migrated up to, but not including,
+// the binary decks migration, and the path of that migration.
+func migrateToBinaryDecks(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+	conn := dbtest.OpenEmpty(t)
+	ups := dbtest.Migrations(t, "up")
+	i := slices.IndexFunc(ups, func(m string) bool { return strings.HasSuffix(m, "_binary_decks.up.sql") })
+	require.GreaterOrEqual(t, i, 0)
+	dbtest.Migrate(t, conn, ups[:i]...)
+	return conn, ups[i]
+}
+
+func TestBinaryDecksMigration(t *testing.T) {
+	conn, up := migrateToBinaryDecks(t)
+
+	dealt := game.NewGame(game.Rules{Variant: game.VariantClassic, HandSize: 3}, game.Seating{}, storetest.Host, game.NewRiffleShuffler())
+	_, err := conn.Exec(`INSERT INTO sessions (id) VALUES (?)`, storetest.Host)
+	require.NoError(t, err)
+	_, err = conn.Exec(`INSERT INTO games (id, hand_size) VALUES (1, 3)`)
+	require.NoError(t, err)
+	for _, p := range dealt.Players() {
+		_, err = conn.Exec(`INSERT INTO game_sessions (game_id, session_id, role, deck, hand) VALUES (1, ?, ?, ?, ?)`,
+			sql.NullString{String: p.SessionID, Valid: p.SessionID != ""}, p.Role, p.Deck.String(), p.Hand.String())
+		require.NoError(t, err)
+	}
+	dbtest.Migrate(t, conn, up)
+
+	g, err := NewSQL(conn, conn).GetGame(context.Background(), 1)
+	require.NoError(t, err)
+	for _, want := range dealt.Players() {
+		got := g.Player1
+		if want.Role == game.Guest {
+			got = g.Player2
+		}
+		assert.Equal(t, want.Deck, got.Deck)
+		assert.Equal(t, want.Hand, got.Hand)
+	}
+	assert.NoError(t, g.CheckCards())
+
+	dbtest.Migrate(t, conn, strings.Replace(up, ".up.sql", ".down.sql", 1))
+	for _, p := range dealt.Players() {
+		var deck, hand string
+		err = conn.QueryRow(`SELECT deck, hand FROM game_sessions WHERE role = ?`, p.Role).Scan(&deck, &hand)
+		require.NoError(t, err)
+		assert.Equal(t, p.Deck.String(), deck)
+		assert.Equal(t, p.Hand.String(), hand)
+	}
+}
+
+func TestBinaryDecksMigrationUnknownSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		deck string
+		hand string
+	}{
+		{name: "deck", deck: "AS,1X,2C", hand: ""},
+		{name: "hand", deck: "AS", hand: "KD,ZZ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, up := migrateToBinaryDecks(t)
+			_, err := conn.Exec(`INSERT INTO games (id, hand_size) VALUES (1, 3)`)
+			require.NoError(t, err)
+			_, err = conn.Exec(`INSERT INTO game_sessions (game_id, role, deck, hand) VALUES (1, ?, ?, ?)`,
+				game.Guest, tt.deck, tt.hand)
+			require.NoError(t, err)
+
+			stmt, err := os.ReadFile(up)
+			require.NoError(t, err)
+			_, err = conn.Exec(string(stmt))
+			assert.ErrorContains(t, err, "decks_and_hands_hold_only_card_slugs")
+		})
+	}
+}
@@ -0,0 +1,360 @@
+// Package storetest checks that implementations of game.GameStore behave alike,
+// so games play the same whichever store keeps them.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seanjh/war/internal/game"
+)
+
+// Host and Guest are the sessions seated at the games of the tests. Stores
+// checking sessions must accept them.
+const (
+	Host  = "host"
+	Guest = "guest"
+)
+
+// Run runs the conformance tests against stores returned by newStore, which must
+// return an empty store each time it is called.
+func Run(t *testing.T, newStore func(t *testing.T) game.GameStore) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s game.GameStore)
+	}{
+		{"CreateGame", testCreateGame},
+		{"GetGameNotFound", testGetGameNotFound},
+		{"GetGameIDByCode", testGetGameIDByCode},
+		{"SaveGame", testSaveGame},
+		{"Rounds", testRounds},
+		{"SaveGameConflicts", testSaveGameConflicts},
+		{"CardsConserved", testCardsConserved},
+		{"ConcurrentSaves", testConcurrentSaves},
+		{"GameService", testGameService},
+		{"GameServiceReloads", testGameServiceReloads},
+		{"GameServiceIdles", testGameServiceIdles},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStore(t))
+		})
+	}
+}
+
+// classic is the rules of the games the tests play, unless they need others.
+var classic = game.Rules{Variant: game.VariantClassic}
+
+func newGame(t *testing.T, s game.GameStore, rules game.Rules, seating game.Seating) *game.Game {
+	t.Helper()
+	g := game.NewGame(rules, seating, Host, game.NewRiffleShuffler())
+	require.NoError(t, s.CreateGame(context.Background(), g))
+	return g
+}
+
+// assertGame checks that the stored game got is the game want. An empty deck
+// may be stored as nil.
+func assertGame(t *testing.T, want, got *game.Game) {
+	t.Helper()
+	assert.Equal(t, want.ID, got.ID)
+	assert.Equal(t, want.Code, got.Code)
+	assert.Equal(t, want.Rules, got.Rules)
+	assert.Equal(t, want.HotSeat, got.HotSeat)
+	assert.Equal(t, want.Version, got.Version)
+	assert.Equal(t, want.Rounds, got.Rounds)
+	require.Equal(t, len(want.Players()), len(got.Players()))
+	for i, p := range want.Players() {
+		q := got.Players()[i]
+		assert.Equal(t, p.Role, q.Role)
+		assert.Equal(t, p.SessionID, q.SessionID, p.Role)
+		assert.Equal(t, p.Bot, q.Bot, p.Role)
+		assert.Equal(t, p.Flipped, q.Flipped, p.Role)
+		assert.Equal(t, p.Deck.String(), q.Deck.String(), p.Role)
+		assert.Equal(t, p.Hand.String(), q.Hand.String(), p.Role)
+		assert.Equal(t, p.Commitment, q.Commitment, p.Role)
+		assert.Equal(t, p.PendingChoice(), q.PendingChoice(), p.Role)
+	}
+}
+
+func testCreateGame(t *testing.T, s game.GameStore) {
+	rules := game.Rules{Variant: game.VariantPeace, SuitOrder: game.SuitOrder{"S", "H", "D", "C"}, HandSize: 3}
+	g := newGame(t, s, rules, game.Seating{GuestBot: "bot"})
+	other := newGame(t, s, classic, game.Seating{HotSeat: true})
+	assert.NotZero(t, g.ID)
+	assert.NotEmpty(t, g.Code)
+	assert.NotEqual(t, g.ID, other.ID)
+	assert.NotEqual(t, g.Code, other.Code)
+
+	loaded, err := s.GetGame(context.Background(), g.ID)
+	require.NoError(t, err)
+	assertGame(t, g, loaded)
+	loaded, err = s.GetGame(context.Background(), other.ID)
+	require.NoError(t, err)
+	assertGame(t, other, loaded)
+}
+
+func testGetGameNotFound(t *testing.T, s game.GameStore) {
+	_, err := s.GetGame(context.Background(), 1)
+	assert.ErrorIs(t, err, game.ErrGameNotFound)
+}
+
+func testGetGameIDByCode(t *testing.T, s game.GameStore) {
+	g := newGame(t, s, classic, game.Seating{})
+	id, err := s.GetGameIDByCode(context.Background(), g.Code)
+	require.NoError(t, err)
+	assert.Equal(t, g.ID, id)
+
+	_, err = s.GetGameIDByCode(context.Background(), "NOPE")
+	assert.ErrorIs(t, err, game.ErrGameNotFound)
+}
+
+// update loads the game, applies the change, and saves it.
+func update(t *testing.T, s game.GameStore, id int, apply func(*game.Game)) (*game.Game, error) {
+	t.Helper()
+	g, err := s.GetGame(context.Background(), id)
+	require.NoError(t, err)
+	apply(g)
+	return g, s.SaveGame(context.Background(), g)
+}
+
+func testSaveGame(t *testing.T, s game.GameStore) {
+	ctx := context.Background()
+	g := newGame(t, s, game.Rules{Variant: game.VariantClassic, HandSize: 3}, game.Seating{})
+
+	saved, err := update(t, s, g.ID, func(g *game.Game) {
+		g.Player2.SessionID = Guest
+		g.Player2.Flipped = true
+		require.NoError(t, g.ChooseFor(g.Player1, g.Player1.Hand[0], "nonce"))
+	})
+	require.NoError(t, err)
+	assert.Equal(t, g.Version+1, saved.Version)
+
+	loaded, err := s.GetGame(ctx, g.ID)
+	require.NoError(t, err)
+	assertGame(t, saved, loaded)
+	assert.Equal(t, Guest, loaded.Player2.SessionID)
+	assert.Equal(t, &game.Choice{Card: g.Player1.Hand[0], Nonce: "nonce"}, loaded.Player1.PendingChoice())
+
+	// Games returned by the store are copies.
+	loaded.Player1.Deck = nil
+	again, err := s.GetGame(ctx, g.ID)
+	require.NoError(t, err)
+	assertGame(t, saved, again)
+}
+
+func testRounds(t *testing.T, s game.GameStore) {
+	ctx := context.Background()
+	g := newGame(t, s, classic, game.Seating{})
+	rounds, err := s.GetRounds(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Empty(t, rounds)
+
+	var played []*game.Battle
+	for range 3 {
+		saved, err := update(t, s, g.ID, func(g *game.Game) {
+			g.Player1.Flipped, g.Player2.Flipped = true, true
+			require.NoError(t, g.PlayReadyRound())
+		})
+		require.NoError(t, err)
+		require.NotNil(t, saved.Battle)
+		played = append(played, saved.Battle)
+	}
+	// Games saved without a Battle add no round.
+	_, err = update(t, s, g.ID, func(*game.Game) {})
+	require.NoError(t, err)
+	loaded, err := s.GetGame(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Equal(t, len(played), loaded.Rounds)
+
+	rounds, err = s.GetRounds(ctx, g.ID)
+	require.NoError(t, err)
+	require.Len(t, rounds, len(played))
+	for i, b := range played {
+		assert.Equal(t, b.Winner, rounds[i].Winner)
+		assert.Equal(t, b.Battle, rounds[i].Battle)
+		assert.Equal(t, b.Log, rounds[i].Log)
+	}
+}
+
+// testSaveGameConflicts saves the game twice from the same version, and checks
+// that the second save changes nothing.
+func testSaveGameConflicts(t *testing.T, s game.GameStore) {
+	ctx := context.Background()
+	g := newGame(t, s, classic, game.Seating{})
+	first, err := s.GetGame(ctx, g.ID)
+	require.NoError(t, err)
+	second, err := s.GetGame(ctx, g.ID)
+	require.NoError(t, err)
+
+	first.Player1.Flipped = true
+	require.NoError(t, s.SaveGame(ctx, first))
+	second.Player2.Flipped = true
+	assert.ErrorIs(t, s.SaveGame(ctx, second), game.ErrConflict)
+
+	loaded, err := s.GetGame(ctx, g.ID)
+	require.NoError(t, err)
+	assertGame(t, first, loaded)
+	assert.False(t, loaded.Player2.Flipped)
+}
+
+// testConcurrentSaves moves cards between the decks from many goroutines, and
+// checks that every save either conflicted or was kept, without losing any.
+func testCardsConserved(t *testing.T, s game.GameStore) {
+	ctx := context.Background()
+	dealt := game.NewGame(classic, game.Seating{}, Host, game.NewRiffleShuffler())
+	dealt.Player2.Deck = append(dealt.Player2.Deck, dealt.Player1.Deck[0])
+	assert.ErrorIs(t, s.CreateGame(ctx, dealt), game.ErrCardsNotConserved)
+
+	g := newGame(t, s, classic, game.Seating{})
+	for _, apply := range []func(*game.Game){
+		func(g *game.Game) { g.Player1.Deck = g.Player1.Deck[1:] },
+		func(g *game.Game) { g.Player2.Deck[0] = g.Player1.Deck[0] },
+		func(g *game.Game) { g.Player1.Hand = append(g.Player1.Hand, game.Card{Suit: "X", Value: 1}) },
+	} {
+		_, err := update(t, s, g.ID, apply)
+		assert.ErrorIs(t, err, game.ErrCardsNotConserved)
+	}
+	loaded, err := s.GetGame(ctx, g.ID)
+	require.NoError(t, err)
+	assertGame(t, g, loaded)
+	assert.NoError(t, loaded.CheckCards())
+}
+
+func testConcurrentSaves(t *testing.T, s game.GameStore) {
+	ctx := context.Background()
+	g := newGame(t, s, classic, game.Seating{})
+	const moves = 20
+
+	var mu sync.Mutex
+	saved := 0
+	var wg sync.WaitGroup
+	for range moves {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := update(t, s, g.ID, func(g *game.Game) {
+				g.Player2.Deck = append(g.Player2.Deck, g.Player1.Deck[0])
+				g.Player1.Deck = g.Player1.Deck[1:]
+			})
+			if errors.Is(err, game.ErrConflict) {
+				return
+			}
+			assert.NoError(t, err)
+			mu.Lock()
+			saved++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	loaded, err := s.GetGame(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Positive(t, saved)
+	assert.Equal(t, g.Version+saved, loaded.Version)
+	assert.Len(t, loaded.Player1.Deck, len(g.Player1.Deck)-saved)
+	assert.Len(t, loaded.Player2.Deck, len(g.Player2.Deck)+saved)
+}
+
+// testGameService plays a game between two sessions through a GameService.
+func testGameService(t *testing.T, s game.GameStore) {
+	ctx := context.Background()
+	games := game.NewGameService(s, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	g, err := games.CreateGame(ctx, Host, classic, game.Seating{})
+	require.NoError(t, err)
+
+	_, err = games.Flip(ctx, g.ID, Guest, game.Unknown)
+	assert.ErrorIs(t, err, game.ErrNotSeated)
+
+	events, cancel := games.Subscribe(g.ID)
+	defer cancel()
+	joined, err := games.JoinGame(ctx, " "+g.Code+" ", Guest)
+	require.NoError(t, err)
+	assert.Equal(t, Guest, joined.Player2.SessionID)
+	assert.Equal(t, game.EventJoined, (<-events).Type)
+	_, err = games.JoinGame(ctx, g.Code, "someone")
+	assert.ErrorIs(t, err, game.ErrSeatTaken)
+	_, err = games.JoinGame(ctx, "NOPE", "someone")
+	assert.ErrorIs(t, err, game.ErrGameNotFound)
+
+	_, err = games.Flip(ctx, g.ID, Host, game.Unknown)
+	require.NoError(t, err)
+	played, err := games.Flip(ctx, g.ID, Guest, game.Unknown)
+	require.NoError(t, err)
+	require.NotNil(t, played.Battle)
+	assert.Equal(t, game.EventMoved, (<-events).Type)
+	assert.Equal(t, game.EventMoved, (<-events).Type)
+	assert.Equal(t, game.EventRound, (<-events).Type)
+
+	loaded, err := games.GetGame(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Nil(t, loaded.Battle)
+	assert.Equal(t, 52, len(loaded.Player1.Deck)+len(loaded.Player2.Deck))
+	rounds, err := games.GetRounds(ctx, g.ID)
+	require.NoError(t, err)
+	require.Len(t, rounds, 1)
+	assert.Equal(t, played.Battle.Winner, rounds[0].Winner)
+
+	_, err = games.GetRounds(ctx, g.ID+1)
+	assert.ErrorIs(t, err, game.ErrGameNotFound)
+}
+
+// testGameServiceReloads saves a game behind the back of its actor, like another
+// server would, and checks that the next move plays the game as saved.
+func testGameServiceReloads(t *testing.T, s game.GameStore) {
+	ctx := context.Background()
+	games := game.NewGameService(s, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	g, err := games.CreateGame(ctx, Host, classic, game.Seating{HotSeat: true})
+	require.NoError(t, err)
+	_, err = games.Flip(ctx, g.ID, Host, game.Host)
+	require.NoError(t, err)
+	assert.Equal(t, 1, games.ActiveGames())
+
+	_, err = update(t, s, g.ID, func(g *game.Game) {
+		g.Player1.Deck = append(g.Player1.Deck, g.Player2.Deck[0])
+		g.Player2.Deck = g.Player2.Deck[1:]
+	})
+	require.NoError(t, err)
+
+	played, err := games.Flip(ctx, g.ID, Host, game.Guest)
+	require.NoError(t, err)
+	require.NotNil(t, played.Battle)
+	assert.Equal(t, 52, len(played.Player1.Deck)+len(played.Player2.Deck))
+	loaded, err := s.GetGame(ctx, g.ID)
+	require.NoError(t, err)
+	assertGame(t, played, loaded)
+}
+
+// testGameServiceIdles checks that actors stop once their game is idle, and that
+// the game is then read from the store.
+func testGameServiceIdles(t *testing.T, s game.GameStore) {
+	ctx := context.Background()
+	games := game.NewGameService(s, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	games.IdleTimeout = 10 * time.Millisecond
+	g, err := games.CreateGame(ctx, Host, classic, game.Seating{HotSeat: true})
+	require.NoError(t, err)
+	assert.Zero(t, games.ActiveGames())
+
+	flipped, err := games.Flip(ctx, g.ID, Host, game.Host)
+	require.NoError(t, err)
+	assert.Equal(t, 1, games.ActiveGames())
+	require.Eventually(t, func() bool { return games.ActiveGames() == 0 }, time.Second, time.Millisecond)
+
+	loaded, err := games.GetGame(ctx, g.ID)
+	require.NoError(t, err)
+	assertGame(t, flipped, loaded)
+	assert.Zero(t, games.ActiveGames())
+
+	// Moves in games that fail to load stop their actor at once.
+	_, err = games.Flip(ctx, g.ID+1, Host, game.Host)
+	assert.ErrorIs(t, err, game.ErrGameNotFound)
+	assert.Eventually(t, func() bool { return games.ActiveGames() == 0 }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,248 @@
+// Package tui draws War games in a terminal, for the warcli command and the SSH
+// frontend of the server.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/seanjh/war/internal/game"
+	"github.com/seanjh/war/pkg/warclient"
+)
+
+type gameMsg struct{ game *warclient.Game }
+
+type eventMsg struct{ event warclient.Event }
+
+type streamClosedMsg struct{}
+
+type errMsg struct{ err error }
+
+// board is the TUI for one game. It reloads the game whenever the event stream
+// reports a change, so moves made elsewhere show up as they happen. The board
+// closes the stream when it quits.
+type board struct {
+	ctx    context.Context
+	client *warclient.Client
+	stream *warclient.Stream
+	ascii  bool
+
+	game *warclient.Game
+	// last is the most recent round played.
+	last   *warclient.Battle
+	status string
+	err    error
+}
+
+// NewBoard returns the board for the game g, updated from the stream of its events.
+// Cards are drawn as ASCII art when ascii is set.
+func NewBoard(ctx context.Context, client *warclient.Client, stream *warclient.Stream, g *warclient.Game, ascii bool) tea.Model {
+	return &board{ctx: ctx, client: client, stream: stream, game: g, last: g.Battle, ascii: ascii}
+}
+
+func (b *board) Init() tea.Cmd {
+	return b.next
+}
+
+// next waits for the next event of the game.
+func (b *board) next() tea.Msg {
+	e, err := b.stream.Next()
+	if errors.Is(err, io.EOF) {
+		return streamClosedMsg{}
+	}
+	if err != nil {
+		return errMsg{err}
+	}
+	return eventMsg{e}
+}
+
+func (b *board) reload() tea.Msg {
+	g, err := b.client.GetGame(b.ctx, b.game.ID)
+	if err != nil {
+		return errMsg{err}
+	}
+	return gameMsg{g}
+}
+
+// seat returns the first seat the session controls that has not moved yet, or
+// nil when the session is waiting on the other players.
+func (b *board) seat() *warclient.Player {
+	for i, p := range b.game.Players {
+		if p.Controllable && !p.Moved {
+			return &b.game.Players[i]
+		}
+	}
+	return nil
+}
+
+func (b *board) move(apply func(p *warclient.Player) (*warclient.Game, error)) tea.Cmd {
+	p := b.seat()
+	if p == nil || b.game.Winner != game.Unknown || b.game.Drawn {
+		return nil
+	}
+	return func() tea.Msg {
+		g, err := apply(p)
+		if err != nil {
+			return errMsg{err}
+		}
+		return gameMsg{g}
+	}
+}
+
+func (b *board) flip() tea.Cmd {
+	return b.move(func(p *warclient.Player) (*warclient.Game, error) {
+		return b.client.Flip(b.ctx, b.game.ID, p.Role)
+	})
+}
+
+func (b *board) choose(i int) tea.Cmd {
+	return b.move(func(p *warclient.Player) (*warclient.Game, error) {
+		if i >= len(p.Hand) {
+			return nil, fmt.Errorf("there is no card %d in the %s's hand", i+1, p.Role)
+		}
+		return b.client.Choose(b.ctx, b.game.ID, p.Role, p.Hand[i])
+	})
+}
+
+func (b *board) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch key := msg.String(); key {
+		case "q", "ctrl+c", "esc":
+			b.stream.Close()
+			return b, tea.Quit
+		case "f", " ":
+			if b.game.Rules.HandSize == 0 {
+				return b, b.flip()
+			}
+		case "r":
+			return b, b.reload
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			if b.game.Rules.HandSize > 0 {
+				return b, b.choose(int(key[0] - '1'))
+			}
+		}
+	case gameMsg:
+		b.game = msg.game
+		b.err = nil
+		if msg.game.Battle != nil {
+			b.last = msg.game.Battle
+		}
+	case eventMsg:
+		b.status = describeEvent(msg.event)
+		if msg.event.Battle != nil {
+			b.last = msg.event.Battle
+		}
+		return b, tea.Batch(b.reload, b.next)
+	case streamClosedMsg:
+		b.status = "The server closed the event stream; press r to refresh."
+	case errMsg:
+		b.err = msg.err
+	}
+	return b, nil
+}
+
+func describeEvent(e warclient.Event) string {
+	switch e.Type {
+	case game.EventJoined:
+		return fmt.Sprintf("The %s joined.", e.Role)
+	case game.EventMoved:
+		return fmt.Sprintf("The %s moved.", e.Role)
+	case game.EventRound:
+		if e.Battle != nil && e.Battle.Winner != game.Unknown {
+			return fmt.Sprintf("The %s won the round.", e.Battle.Winner)
+		}
+		return "A round was played."
+	}
+	return string(e.Type)
+}
+
+func describePlayer(p warclient.Player) string {
+	var who string
+	switch {
+	case p.Bot != "":
+		who = fmt.Sprintf("bot %s", p.Bot)
+	case p.Controllable:
+		who = "you"
+	case p.Seated:
+		who = "seated"
+	default:
+		who = "open seat"
+	}
+	line := fmt.Sprintf("%-5s (%s)  deck %2d", p.Role, who, p.DeckSize)
+	if p.HandSize > 0 {
+		line += fmt.Sprintf("  hand %d", p.HandSize)
+	}
+	if p.Moved {
+		line += "  [moved]"
+	}
+	return line
+}
+
+func (b *board) View() string {
+	var s strings.Builder
+	g := b.game
+	fmt.Fprintf(&s, "War: game %d, code %s, %s", g.ID, g.Code, g.Rules.Variant.Name())
+	if g.Rules.HandSize > 0 {
+		fmt.Fprintf(&s, ", hands of %d", g.Rules.HandSize)
+	}
+	if g.HotSeat {
+		s.WriteString(", hot seat")
+	}
+	s.WriteString("\n\n")
+	for _, p := range g.Players {
+		s.WriteString(describePlayer(p) + "\n")
+	}
+
+	if b.last != nil {
+		s.WriteString("\nLast round\n")
+		var cards game.Deck
+		var labels []string
+		for _, p := range g.Players {
+			if c, ok := b.last.Cards[p.Role]; ok {
+				cards = append(cards, c)
+				labels = append(labels, p.Role.String())
+			}
+		}
+		s.WriteString(renderCards(cards, labels, b.ascii) + "\n")
+		for _, line := range b.last.Log {
+			s.WriteString("  " + line + "\n")
+		}
+	}
+
+	if p := b.seat(); p != nil && len(p.Hand) > 0 {
+		fmt.Fprintf(&s, "\nThe %s's hand\n", p.Role)
+		labels := make([]string, len(p.Hand))
+		for i := range p.Hand {
+			labels[i] = fmt.Sprintf("[%d]", i+1)
+		}
+		s.WriteString(renderCards(p.Hand, labels, b.ascii) + "\n")
+	}
+
+	s.WriteString("\n")
+	switch p := b.seat(); {
+	case g.Winner != game.Unknown:
+		fmt.Fprintf(&s, "The %s won the game!\n", g.Winner)
+	case g.Drawn:
+		s.WriteString("The game was drawn with no winner.\n")
+	case p == nil:
+		s.WriteString("Waiting for the other player...\n")
+	case g.Rules.HandSize > 0:
+		fmt.Fprintf(&s, "%s to move: press 1-%d to choose a card.\n", p.Role, len(p.Hand))
+	default:
+		fmt.Fprintf(&s, "%s to move: press f to flip.\n", p.Role)
+	}
+	if b.status != "" {
+		s.WriteString(b.status + "\n")
+	}
+	if b.err != nil {
+		fmt.Fprintf(&s, "Error: %v\n", b.err)
+	}
+	s.WriteString("r refresh, q quit\n")
+	return s.String()
+}
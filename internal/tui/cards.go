@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/seanjh/war/internal/game"
+)
+
+var redCard = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+
+// glyphBases are the code points before the ace of each suit in the Unicode
+// Playing Cards block.
+var glyphBases = map[game.Suit]rune{
+	game.SuitSpade:   0x1F0A0,
+	game.SuitHeart:   0x1F0B0,
+	game.SuitDiamond: 0x1F0C0,
+	game.SuitClub:    0x1F0D0,
+}
+
+// glyph returns the Unicode playing card for c, or its slug when there is none.
+func glyph(c game.Card) string {
+	base, ok := glyphBases[c.Suit]
+	if !ok || c.Value < 2 || c.Value > game.Ace {
+		return c.Slug()
+	}
+	offset := rune(c.Value)
+	switch c.Value {
+	case game.Ace:
+		offset = 1
+	case game.Queen, game.King:
+		// The knight sits between the jack and the queen.
+		offset++
+	}
+	return string(base + offset)
+}
+
+// cardArt returns a drawing of c, one line per row. The drawing is plain ASCII
+// when ascii is set, and a Unicode glyph with the card name otherwise.
+func cardArt(c game.Card, ascii bool) []string {
+	if !ascii {
+		return []string{fmt.Sprintf("%s  %s", glyph(c), c.Name())}
+	}
+	value := c.Value.Slug()
+	return []string{
+		"+-------+",
+		fmt.Sprintf("|%-7s|", value),
+		fmt.Sprintf("|   %s   |", c.Suit),
+		fmt.Sprintf("|%7s|", value),
+		"+-------+",
+		fmt.Sprintf("%-9s", c.Name()),
+	}
+}
+
+// renderCard draws c, in red for hearts and diamonds.
+func renderCard(c game.Card, ascii bool) string {
+	art := strings.Join(cardArt(c, ascii), "\n")
+	if c.Suit == game.SuitHeart || c.Suit == game.SuitDiamond {
+		return redCard.Render(art)
+	}
+	return art
+}
+
+// renderCards draws the cards side by side when ascii is set, and one per line
+// otherwise. Each card is captioned with its label, when there is one.
+func renderCards(cards game.Deck, labels []string, ascii bool) string {
+	drawn := make([]string, len(cards))
+	for i, c := range cards {
+		card := renderCard(c, ascii)
+		if i < len(labels) {
+			if ascii {
+				card = lipgloss.JoinVertical(lipgloss.Left, labels[i], card)
+			} else {
+				card = labels[i] + " " + card
+			}
+		}
+		drawn[i] = card
+	}
+	if !ascii {
+		return strings.Join(drawn, "\n")
+	}
+	for i := range drawn[:max(0, len(drawn)-1)] {
+		drawn[i] = lipgloss.NewStyle().PaddingRight(2).Render(drawn[i])
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, drawn...)
+}
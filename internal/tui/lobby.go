@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/seanjh/war/internal/bot"
+	"github.com/seanjh/war/internal/game"
+	"github.com/seanjh/war/pkg/warclient"
+)
+
+// opponents lists the choices for the guest seat of a hosted game, where "" leaves
+// it open for another player to join.
+var opponents = []string{"", bot.NameAutoFlip, bot.NameRandom, bot.NameGreedy, bot.NameMonteCarlo}
+
+type openedMsg struct {
+	game   *warclient.Game
+	stream *warclient.Stream
+}
+
+// lobby picks the rules for a new game to host, or the code of a game to join,
+// and then hands over to the board of the game.
+type lobby struct {
+	ctx    context.Context
+	client *warclient.Client
+	ascii  bool
+
+	variant  int
+	handSize int
+	opponent int
+	// joining is set while the game code is typed.
+	joining bool
+	code    string
+	busy    bool
+	err     error
+}
+
+// NewLobby returns a lobby where the client's session can host or join a game.
+// Cards are drawn as ASCII art when ascii is set.
+func NewLobby(ctx context.Context, client *warclient.Client, ascii bool) tea.Model {
+	return &lobby{ctx: ctx, client: client, ascii: ascii}
+}
+
+func (l *lobby) Init() tea.Cmd {
+	return nil
+}
+
+// open returns a command opening the game returned by start and subscribing to
+// its events.
+func (l *lobby) open(start func() (*warclient.Game, error)) tea.Cmd {
+	l.busy = true
+	l.err = nil
+	return func() tea.Msg {
+		g, err := start()
+		if err != nil {
+			return errMsg{err}
+		}
+		stream, err := l.client.Subscribe(l.ctx, g.ID)
+		if err != nil {
+			return errMsg{err}
+		}
+		return openedMsg{game: g, stream: stream}
+	}
+}
+
+func (l *lobby) host() tea.Cmd {
+	rules, err := game.NewRules(string(game.Variants[l.variant]), l.handSize, "")
+	if err != nil {
+		l.err = err
+		return nil
+	}
+	opts := warclient.CreateGameOptions{Rules: rules, Opponent: opponents[l.opponent]}
+	return l.open(func() (*warclient.Game, error) {
+		return l.client.CreateGame(l.ctx, opts)
+	})
+}
+
+func (l *lobby) join() tea.Cmd {
+	code := l.code
+	return l.open(func() (*warclient.Game, error) {
+		return l.client.JoinGame(l.ctx, code)
+	})
+}
+
+func (l *lobby) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if l.busy {
+			if msg.Type == tea.KeyCtrlC {
+				return l, tea.Quit
+			}
+			return l, nil
+		}
+		if l.joining {
+			return l, l.typeCode(msg)
+		}
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return l, tea.Quit
+		case "v":
+			l.variant = (l.variant + 1) % len(game.Variants)
+		case "n":
+			l.handSize = (l.handSize + 1) % (game.MaxHandSize + 1)
+		case "o":
+			l.opponent = (l.opponent + 1) % len(opponents)
+		case "h":
+			return l, l.host()
+		case "j":
+			l.joining = true
+			l.err = nil
+		}
+	case openedMsg:
+		b := NewBoard(l.ctx, l.client, msg.stream, msg.game, l.ascii)
+		return b, b.Init()
+	case errMsg:
+		l.busy = false
+		l.err = msg.err
+	}
+	return l, nil
+}
+
+// typeCode edits the game code being typed.
+func (l *lobby) typeCode(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+	case tea.KeyEsc:
+		l.joining = false
+	case tea.KeyEnter:
+		if l.code != "" {
+			return l.join()
+		}
+	case tea.KeyBackspace:
+		if l.code != "" {
+			l.code = l.code[:len(l.code)-1]
+		}
+	case tea.KeyRunes:
+		l.code += strings.ToUpper(string(msg.Runes))
+	}
+	return nil
+}
+
+func (l *lobby) View() string {
+	var s strings.Builder
+	s.WriteString("War\n\n")
+	fmt.Fprintf(&s, "  Variant   %-28s v to change\n", game.Variants[l.variant].Name())
+	hand := "flip the top card"
+	if l.handSize > 0 {
+		hand = fmt.Sprintf("choose from %d cards", l.handSize)
+	}
+	fmt.Fprintf(&s, "  Hand      %-28s n to change\n", hand)
+	opponent := "another player"
+	if name := opponents[l.opponent]; name != "" {
+		opponent = "bot " + name
+	}
+	fmt.Fprintf(&s, "  Opponent  %-28s o to change\n\n", opponent)
+
+	switch {
+	case l.busy:
+		s.WriteString("Opening the game...\n")
+	case l.joining:
+		fmt.Fprintf(&s, "Game code: %s_\n", l.code)
+		s.WriteString("enter join, esc cancel\n")
+	default:
+		s.WriteString("h host a game, j join a game by code, q quit\n")
+	}
+	if l.err != nil {
+		fmt.Fprintf(&s, "Error: %v\n", l.err)
+	}
+	return s.String()
+}
@@ -0,0 +1,243 @@
+// Package warclient is a client for the JSON API of the War server.
+package warclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seanjh/war/internal/api"
+	"github.com/seanjh/war/internal/game"
+)
+
+const sessionCookieName = "session-id"
+
+// Error is a failed request, as reported by the server. Code is one of the API
+// error codes, like "not_seated", and empty when the server did not send one.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("war: status %d: %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("war: %s (status %d): %s", e.Code, e.Status, e.Message)
+}
+
+// Client calls the War server as one session. The session is opened by the first
+// game created or joined, unless one is set with SetSessionID.
+//
+// Requests the server refused without handling them (429 and 503) are retried.
+// GET requests are also retried after network errors, 502, and 504.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// MaxRetries is the number of times a failed request is retried.
+	MaxRetries int
+	// Backoff is the wait before the first retry, which doubles for each retry.
+	Backoff time.Duration
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		MaxRetries: 3,
+		Backoff:    100 * time.Millisecond,
+	}
+}
+
+// SessionID returns the ID of the client's session, or "" before one is opened.
+func (c *Client) SessionID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sessionID
+}
+
+// SetSessionID makes the client act as an existing session.
+func (c *Client) SetSessionID(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sessionID = id
+}
+
+// retryable reports whether a request may be sent again after it failed with
+// the status, or with a network error when status is zero.
+func retryable(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case 0, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return method == http.MethodGet
+	}
+	return false
+}
+
+// send sends the request, retrying when allowed, and returns the first response
+// that is not retried. The caller closes the response body.
+func (c *Client) send(ctx context.Context, method, path string, body []byte, accept string) (*http.Response, error) {
+	wait := c.Backoff
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", accept)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if id := c.SessionID(); id != "" {
+			req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: id})
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		status := 0
+		if err == nil {
+			status = resp.StatusCode
+			for _, cookie := range resp.Cookies() {
+				if cookie.Name == sessionCookieName {
+					c.SetSessionID(cookie.Value)
+				}
+			}
+			if status < 400 {
+				return resp, nil
+			}
+		}
+		if attempt >= c.MaxRetries || !retryable(method, status) || ctx.Err() != nil {
+			if err != nil {
+				return nil, fmt.Errorf("failed to %s %s: %w", method, path, err)
+			}
+			return resp, nil
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// readError returns the Error reported by the failed response.
+func readError(resp *http.Response) error {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read error response: %w", err)
+	}
+	var body api.Error
+	if json.Unmarshal(data, &body) == nil && body.Error.Code != "" {
+		return &Error{Status: resp.StatusCode, Code: body.Error.Code, Message: body.Error.Message}
+	}
+	return &Error{Status: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+}
+
+// do sends the request with in as its JSON body, unless it is nil, and decodes the
+// JSON response into out.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+	resp, err := c.send(ctx, method, path, body, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return readError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) doGame(ctx context.Context, method, path string, in any) (*Game, error) {
+	var data api.Game
+	if err := c.do(ctx, method, path, in, &data); err != nil {
+		return nil, err
+	}
+	return newGame(data)
+}
+
+// CreateGameOptions describes a new game. The guest seat is open for another
+// session to join, unless Opponent names a bot or HotSeat is set.
+type CreateGameOptions struct {
+	Rules    game.Rules
+	Opponent string
+	HotSeat  bool
+}
+
+// CreateGame creates a game hosted by the client's session.
+func (c *Client) CreateGame(ctx context.Context, opts CreateGameOptions) (*Game, error) {
+	return c.doGame(ctx, http.MethodPost, "/api/v1/games", api.CreateGameRequest{
+		Variant:   string(opts.Rules.Variant),
+		HandSize:  opts.Rules.HandSize,
+		SuitOrder: opts.Rules.SuitOrder.String(),
+		Opponent:  opts.Opponent,
+		HotSeat:   opts.HotSeat,
+	})
+}
+
+// JoinGame takes the open guest seat of the game with the code.
+func (c *Client) JoinGame(ctx context.Context, code string) (*Game, error) {
+	return c.doGame(ctx, http.MethodPost, "/api/v1/games/join", api.JoinGameRequest{Code: code})
+}
+
+// GetGame returns the game as seen by the client's session.
+func (c *Client) GetGame(ctx context.Context, gameID int) (*Game, error) {
+	return c.doGame(ctx, http.MethodGet, fmt.Sprintf("/api/v1/games/%d", gameID), nil)
+}
+
+// Rounds returns every round played in the game, oldest first.
+func (c *Client) Rounds(ctx context.Context, gameID int) ([]Battle, error) {
+	var data api.Rounds
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/v1/games/%d/rounds", gameID), nil, &data); err != nil {
+		return nil, err
+	}
+	rounds := make([]Battle, len(data.Rounds))
+	for i, r := range data.Rounds {
+		b, err := newBattle(r.Battle)
+		if err != nil {
+			return nil, err
+		}
+		rounds[i] = *b
+	}
+	return rounds, nil
+}
+
+// Flip flips the top card of the deck for the seat with the role. When role is
+// Unknown, the server picks the session's first seat that has not moved yet.
+func (c *Client) Flip(ctx context.Context, gameID int, role game.GameRole) (*Game, error) {
+	return c.doGame(ctx, http.MethodPost, fmt.Sprintf("/api/v1/games/%d/flip", gameID), api.MoveRequest{
+		Role: roleName(role),
+	})
+}
+
+// Choose secretly chooses the card from the hand of the seat with the role.
+func (c *Client) Choose(ctx context.Context, gameID int, role game.GameRole, card game.Card) (*Game, error) {
+	return c.doGame(ctx, http.MethodPost, fmt.Sprintf("/api/v1/games/%d/choose", gameID), api.MoveRequest{
+		Role: roleName(role),
+		Card: card.Slug(),
+	})
+}
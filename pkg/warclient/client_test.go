@@ -0,0 +1,175 @@
+package warclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seanjh/war/internal/api"
+	"github.com/seanjh/war/internal/appcontext"
+	"github.com/seanjh/war/internal/db"
+	"github.com/seanjh/war/internal/db/dbtest"
+	"github.com/seanjh/war/internal/game"
+	"github.com/seanjh/war/internal/store"
+)
+
+// newTestServer returns a server for the API routes, backed by a migrated
+// in-memory database.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	conn := dbtest.Open(t)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	d := &appcontext.AppContextDB{DB: conn, Query: db.New(conn)}
+	ctx := &appcontext.AppContext{
+		Logger:   logger,
+		DBReader: d,
+		DBWriter: d,
+		Games:    game.NewGameService(store.NewSQL(conn, conn), logger),
+	}
+	s := httptest.NewServer(ctx.Middleware(api.SetupRoutes(http.NewServeMux())))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestPlayGame(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	host, guest := New(s.URL), New(s.URL)
+
+	g, err := host.CreateGame(ctx, CreateGameOptions{
+		Rules: game.Rules{Variant: game.VariantPeace, SuitOrder: game.DefaultSuitOrder},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, host.SessionID())
+	assert.Equal(t, game.VariantPeace, g.Rules.Variant)
+	assert.Equal(t, game.DefaultSuitOrder, g.Rules.SuitOrder)
+	assert.False(t, g.Player(game.Guest).Seated)
+
+	stream, err := host.Subscribe(ctx, g.ID)
+	require.NoError(t, err)
+	defer stream.Close()
+
+	g, err = guest.JoinGame(ctx, g.Code)
+	require.NoError(t, err)
+	assert.NotEqual(t, host.SessionID(), guest.SessionID())
+	assert.True(t, g.Player(game.Guest).Controllable)
+
+	_, err = host.Flip(ctx, g.ID, game.Unknown)
+	require.NoError(t, err)
+	g, err = guest.Flip(ctx, g.ID, game.Guest)
+	require.NoError(t, err)
+	require.NotNil(t, g.Battle)
+	assert.Len(t, g.Battle.Cards, 2)
+
+	var events []Event
+	for range 4 {
+		e, err := stream.Next()
+		require.NoError(t, err)
+		events = append(events, e)
+	}
+	assert.Equal(t, game.EventJoined, events[0].Type)
+	assert.Equal(t, game.Guest, events[0].Role)
+	assert.Equal(t, game.EventMoved, events[1].Type)
+	assert.Equal(t, game.Host, events[1].Role)
+	assert.Equal(t, game.EventMoved, events[2].Type)
+	assert.Equal(t, game.EventRound, events[3].Type)
+	assert.Equal(t, g.Battle, events[3].Battle)
+
+	rounds, err := guest.Rounds(ctx, g.ID)
+	require.NoError(t, err)
+	require.Len(t, rounds, 1)
+	assert.Equal(t, g.Battle.Cards, rounds[0].Cards)
+}
+
+func TestChoose(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	c := New(s.URL)
+
+	g, err := c.CreateGame(ctx, CreateGameOptions{Rules: game.Rules{HandSize: 3}, HotSeat: true})
+	require.NoError(t, err)
+	for _, role := range []game.GameRole{game.Host, game.Guest} {
+		p := g.Player(role)
+		require.Len(t, p.Hand, 3)
+		g, err = c.Choose(ctx, g.ID, role, p.Hand[0])
+		require.NoError(t, err)
+	}
+	require.NotNil(t, g.Battle)
+	assert.Len(t, g.Battle.Reveals, 2)
+}
+
+func TestErrors(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	c := New(s.URL)
+
+	_, err := c.GetGame(ctx, 99)
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.Status)
+	assert.Equal(t, "not_found", apiErr.Code)
+
+	g, err := c.CreateGame(ctx, CreateGameOptions{})
+	require.NoError(t, err)
+	_, err = c.Choose(ctx, g.ID, game.Unknown, game.Card{Suit: game.SuitClub, Value: 2})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "invalid_move", apiErr.Code)
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		failures int32
+		attempts int32
+		err      bool
+	}{
+		{"get after unavailable", http.MethodGet, http.StatusServiceUnavailable, 2, 3, false},
+		{"get after bad gateway", http.MethodGet, http.StatusBadGateway, 1, 2, false},
+		{"post after unavailable", http.MethodPost, http.StatusServiceUnavailable, 1, 2, false},
+		{"post after bad gateway", http.MethodPost, http.StatusBadGateway, 1, 1, true},
+		{"gives up", http.MethodGet, http.StatusServiceUnavailable, 10, 4, true},
+		{"client error", http.MethodGet, http.StatusNotFound, 1, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) <= tt.failures {
+					http.Error(w, "try again", tt.status)
+					return
+				}
+				w.Write([]byte(`{"id": 1, "code": "C0DE", "rules": {"variant": "classic", "hand_size": 0}, "players": []}`))
+			}))
+			defer s.Close()
+			c := New(s.URL)
+			c.Backoff = 0
+
+			var err error
+			if tt.method == http.MethodGet {
+				_, err = c.GetGame(context.Background(), 1)
+			} else {
+				_, err = c.Flip(context.Background(), 1, game.Unknown)
+			}
+			assert.Equal(t, tt.attempts, attempts.Load())
+			if !tt.err {
+				assert.NoError(t, err)
+				return
+			}
+			var apiErr *Error
+			require.True(t, errors.As(err, &apiErr), err)
+			assert.Equal(t, tt.status, apiErr.Status)
+			assert.Equal(t, "try again", apiErr.Message)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package warclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/seanjh/war/internal/api"
+)
+
+// Stream reads the events of a game as they happen.
+type Stream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// Subscribe opens a Stream of every change to the game. Every change made after
+// Subscribe returns is sent to the Stream, until it is closed or ctx is done.
+//
+// The stream is held open indefinitely, so the HTTPClient must not have a Timeout.
+func (c *Client) Subscribe(ctx context.Context, gameID int) (*Stream, error) {
+	resp, err := c.send(ctx, http.MethodGet, fmt.Sprintf("/api/v1/games/%d/events", gameID), nil, "text/event-stream")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return nil, readError(resp)
+	}
+	return &Stream{body: resp.Body, scanner: bufio.NewScanner(resp.Body)}, nil
+}
+
+// Next blocks until the next event arrives. It returns io.EOF once the server ends
+// the stream.
+func (s *Stream) Next() (Event, error) {
+	var data strings.Builder
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "" {
+			if data.Len() == 0 {
+				continue
+			}
+			var e api.Event
+			if err := json.Unmarshal([]byte(data.String()), &e); err != nil {
+				return Event{}, fmt.Errorf("failed to decode event: %w", err)
+			}
+			return newEvent(e)
+		}
+		// Event names are repeated in the data, and comments are ignored.
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data.WriteString(strings.TrimPrefix(value, " "))
+		}
+	}
+	if err := s.scanner.Err(); err != nil {
+		return Event{}, fmt.Errorf("failed to read event stream: %w", err)
+	}
+	return Event{}, io.EOF
+}
+
+// Close stops reading the events.
+func (s *Stream) Close() error {
+	return s.body.Close()
+}
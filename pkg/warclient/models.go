@@ -0,0 +1,210 @@
+package warclient
+
+import (
+	"fmt"
+
+	"github.com/seanjh/war/internal/api"
+	"github.com/seanjh/war/internal/game"
+)
+
+// Player is a seat at a game, as seen by the client's session.
+type Player struct {
+	Role     game.GameRole
+	DeckSize int
+	HandSize int
+	// Hand and Chosen are only set for the seats the session controls.
+	Hand         game.Deck
+	Chosen       *game.Card
+	Flipped      bool
+	Moved        bool
+	Commitment   game.Commitment
+	Bot          string
+	Seated       bool
+	Controllable bool
+}
+
+// Battle is a round played. Cards holds the card each role played first, and War
+// the cards each role committed to a war.
+type Battle struct {
+	Cards   map[game.GameRole]game.Card
+	War     map[game.GameRole]game.Deck
+	Winner  game.GameRole
+	Reveals map[game.GameRole]game.Choice
+	Log     []string
+}
+
+type Game struct {
+	ID      int
+	Code    string
+	Rules   game.Rules
+	HotSeat bool
+	Players []Player
+	// Battle is the round played by the request, if any.
+	Battle *Battle
+	Winner game.GameRole
+	// Drawn is true once the game ended without a winner after game.MaxRounds.
+	Drawn bool
+}
+
+// Player returns the seat with the role, or nil.
+func (g *Game) Player(role game.GameRole) *Player {
+	for i := range g.Players {
+		if g.Players[i].Role == role {
+			return &g.Players[i]
+		}
+	}
+	return nil
+}
+
+// Event tells subscribers that a game changed.
+type Event struct {
+	Type   game.EventType
+	GameID int
+	// Role is the seat that joined or moved.
+	Role game.GameRole
+	// Battle is the round played, for game.EventRound.
+	Battle *Battle
+}
+
+func roleName(r game.GameRole) string {
+	if r == game.Unknown {
+		return ""
+	}
+	return r.String()
+}
+
+func parseRole(s string) (game.GameRole, error) {
+	r := game.ParseGameRole(s)
+	if r == game.Unknown && s != "" {
+		return game.Unknown, fmt.Errorf("invalid role '%s'", s)
+	}
+	return r, nil
+}
+
+func parseDeck(slugs []string) (game.Deck, error) {
+	d := make(game.Deck, len(slugs))
+	for i, slug := range slugs {
+		c, err := game.ParseCard(slug)
+		if err != nil {
+			return nil, err
+		}
+		d[i] = c
+	}
+	return d, nil
+}
+
+func newGame(data api.Game) (*Game, error) {
+	rules, err := game.NewRules(data.Rules.Variant, data.Rules.HandSize, data.Rules.SuitOrder)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rules for game %d: %w", data.ID, err)
+	}
+	g := &Game{
+		ID:      data.ID,
+		Code:    data.Code,
+		Rules:   rules,
+		HotSeat: data.HotSeat,
+		Players: make([]Player, len(data.Players)),
+		Drawn:   data.Drawn,
+	}
+	if g.Winner, err = parseRole(data.Winner); err != nil {
+		return nil, err
+	}
+	for i, p := range data.Players {
+		player, err := newPlayer(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid player for game %d: %w", data.ID, err)
+		}
+		g.Players[i] = *player
+	}
+	if data.Battle != nil {
+		if g.Battle, err = newBattle(*data.Battle); err != nil {
+			return nil, fmt.Errorf("invalid battle for game %d: %w", data.ID, err)
+		}
+	}
+	return g, nil
+}
+
+func newPlayer(data api.Player) (*Player, error) {
+	p := &Player{
+		DeckSize:     data.DeckSize,
+		HandSize:     data.HandSize,
+		Flipped:      data.Flipped,
+		Moved:        data.Moved,
+		Commitment:   game.Commitment(data.Commitment),
+		Bot:          data.Bot,
+		Seated:       data.Seated,
+		Controllable: data.Controllable,
+	}
+	var err error
+	if p.Role, err = parseRole(data.Role); err != nil {
+		return nil, err
+	}
+	if p.Hand, err = parseDeck(data.Hand); err != nil {
+		return nil, err
+	}
+	if data.Chosen != "" {
+		c, err := game.ParseCard(data.Chosen)
+		if err != nil {
+			return nil, err
+		}
+		p.Chosen = &c
+	}
+	return p, nil
+}
+
+func newBattle(data api.Battle) (*Battle, error) {
+	b := &Battle{
+		Cards:   make(map[game.GameRole]game.Card, len(data.Cards)),
+		War:     make(map[game.GameRole]game.Deck, len(data.War)),
+		Reveals: make(map[game.GameRole]game.Choice, len(data.Reveals)),
+		Log:     data.Log,
+	}
+	var err error
+	if b.Winner, err = parseRole(data.Winner); err != nil {
+		return nil, err
+	}
+	for name, slug := range data.Cards {
+		role, err := parseRole(name)
+		if err != nil {
+			return nil, err
+		}
+		if b.Cards[role], err = game.ParseCard(slug); err != nil {
+			return nil, err
+		}
+	}
+	for name, slugs := range data.War {
+		role, err := parseRole(name)
+		if err != nil {
+			return nil, err
+		}
+		if b.War[role], err = parseDeck(slugs); err != nil {
+			return nil, err
+		}
+	}
+	for name, reveal := range data.Reveals {
+		role, err := parseRole(name)
+		if err != nil {
+			return nil, err
+		}
+		c, err := game.ParseCard(reveal.Card)
+		if err != nil {
+			return nil, err
+		}
+		b.Reveals[role] = game.Choice{Card: c, Nonce: reveal.Nonce}
+	}
+	return b, nil
+}
+
+func newEvent(data api.Event) (Event, error) {
+	e := Event{Type: game.EventType(data.Type), GameID: data.GameID}
+	var err error
+	if e.Role, err = parseRole(data.Role); err != nil {
+		return Event{}, err
+	}
+	if data.Battle != nil {
+		if e.Battle, err = newBattle(*data.Battle); err != nil {
+			return Event{}, err
+		}
+	}
+	return e, nil
+}